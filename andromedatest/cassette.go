@@ -0,0 +1,427 @@
+package andromedatest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+// Переменная окружения, при значении 1 UseCassette записывает запросы к настоящему серверу
+const RecordEnv = "ANDROMEDA_RECORD"
+
+// Поля, значения которых заменяются при записи
+var sensitiveFields = map[string]bool{"objectpassword": true, "pincode": true}
+
+type (
+	//Записанные запросы и ответы
+	Cassette struct {
+		Interactions []Interaction `json:"interactions"`
+	}
+
+	//Запрос и ответ
+	Interaction struct {
+		Request  RecordedRequest  `json:"request"`
+		Response RecordedResponse `json:"response"`
+	}
+
+	RecordedRequest struct {
+		Method string          `json:"method"`
+		Path   string          `json:"path"`
+		Query  string          `json:"query,omitempty"` //Нормализованные параметры: ключи и значения упорядочены
+		Body   json.RawMessage `json:"body,omitempty"`
+	}
+
+	RecordedResponse struct {
+		Status      int             `json:"status"`
+		ContentType string          `json:"contentType,omitempty"`
+		Body        json.RawMessage `json:"body,omitempty"` //Тело в формате JSON
+		Text        string          `json:"text,omitempty"` //Тело не в формате JSON
+	}
+
+	//Транспорт, который выполняет запросы через Next и записывает их с заменой чувствительных данных:
+	//заголовок apiKey не сохраняется, ObjectPassword и PINCode заменяются на «***», телефоны - на
+	//последовательные псевдонимы в порядке появления (+70000000001, +70000000002...). Одинаковые телефоны
+	//одной записи получают одинаковые псевдонимы; по псевдониму нельзя восстановить телефон
+	Recorder struct {
+		Next http.RoundTripper //По умолчанию http.DefaultTransport
+
+		mu       sync.Mutex
+		cassette Cassette
+		scrub    scrubber
+	}
+
+	//Транспорт, который отвечает записанными ответами без обращения к серверу.
+	//Запрос сопоставляется по методу, пути, нормализованным параметрам и телу без учёта телефонов,
+	//так как их псевдонимы известны только при записи. Одинаковые запросы получают
+	//записанные ответы по порядку, после последнего повторяется последний ответ
+	Replayer struct {
+		mu       sync.Mutex
+		cassette Cassette
+		used     []bool
+		last     map[string]int
+	}
+
+	//Для запроса нет записи
+	UnmatchedError struct {
+		Method string
+		Path   string
+		Query  string
+	}
+
+	//Замена чувствительных данных. Телефоны заменяются псевдонимами из phones, а при phones == nil -
+	//нулями (для сопоставления запросов без учёта телефонов)
+	scrubber struct {
+		phones map[string]string
+	}
+)
+
+func (e *UnmatchedError) Error() string {
+	target := e.Path
+	if e.Query != "" {
+		target += "?" + e.Query
+	}
+	return "andromedatest: нет записи для запроса " + e.Method + " " + target
+}
+
+// Запись или воспроизведение запросов для теста. Если RecordEnv=1, запросы выполняются на сервере
+// и после теста сохраняются в path; иначе ответы берутся из path, и тест завершается ошибкой,
+// если какая-то запись не была использована
+func UseCassette(t testing.TB, path string) http.RoundTripper {
+	t.Helper()
+
+	if os.Getenv(RecordEnv) == "1" {
+		rec := &Recorder{}
+		t.Cleanup(func() {
+			if err := rec.Save(path); err != nil {
+				t.Errorf("не удалось сохранить %s: %v", path, err)
+			}
+		})
+		return rec
+	}
+
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("не удалось загрузить %s: %v", path, err)
+	}
+	rep := NewReplayer(cassette)
+	t.Cleanup(func() {
+		for _, i := range rep.Unused() {
+			t.Errorf("запись не использована: %s %s?%s", i.Request.Method, i.Request.Path, i.Request.Query)
+		}
+	})
+
+	return rep
+}
+
+// Чтение записей из файла
+func LoadCassette(path string) (Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Cassette{}, err
+	}
+
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Cassette{}, &andromeda.Error{Code: andromeda.CodeCassetteFormat, Detail: path, Err: err}
+	}
+
+	return c, nil
+}
+
+// Сохранение записей в файл
+func (c Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := r.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	var reqBody []byte
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		reqBody = data
+		req.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.scrub.phones == nil {
+		r.scrub.phones = map[string]string{}
+	}
+	interaction := Interaction{
+		Request: r.scrub.request(req, reqBody),
+		Response: RecordedResponse{
+			Status:      resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+		},
+	}
+	if body, ok := r.scrub.json(respBody); ok {
+		interaction.Response.Body = body
+	} else {
+		interaction.Response.Text = string(respBody)
+	}
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+
+	return resp, nil
+}
+
+// Записанные запросы
+func (r *Recorder) Cassette() Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return Cassette{Interactions: append([]Interaction(nil), r.cassette.Interactions...)}
+}
+
+// Сохранение записанных запросов в файл
+func (r *Recorder) Save(path string) error {
+	return r.Cassette().Save(path)
+}
+
+func NewReplayer(c Cassette) *Replayer {
+	return &Replayer{cassette: c, used: make([]bool, len(c.Interactions)), last: map[string]int{}}
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = data
+	}
+	var blind scrubber
+	want := blind.request(req, body)
+	key := want.key()
+
+	r.mu.Lock()
+	idx := -1
+	for n, i := range r.cassette.Interactions {
+		if !r.used[n] && i.Request.key() == key {
+			idx = n
+			break
+		}
+	}
+	if idx < 0 {
+		last, ok := r.last[key]
+		if !ok {
+			r.mu.Unlock()
+			return nil, &UnmatchedError{Method: want.Method, Path: want.Path, Query: want.Query}
+		}
+		idx = last
+	}
+	r.used[idx] = true
+	r.last[key] = idx
+	rec := r.cassette.Interactions[idx].Response
+	r.mu.Unlock()
+
+	respBody := []byte(rec.Text)
+	if len(rec.Body) > 0 {
+		respBody = rec.Body
+	}
+	header := http.Header{}
+	if rec.ContentType != "" {
+		header.Set("Content-Type", rec.ContentType)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+		Request:       req,
+	}, nil
+}
+
+// Записи, которые ни разу не были использованы
+func (r *Replayer) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unused []Interaction
+	for n, i := range r.cassette.Interactions {
+		if !r.used[n] {
+			unused = append(unused, i)
+		}
+	}
+
+	return unused
+}
+
+// Запрос в виде записи: параметры нормализованы, чувствительные данные заменены
+func (s *scrubber) request(req *http.Request, body []byte) RecordedRequest {
+	rec := RecordedRequest{Method: req.Method, Path: req.URL.Path, Query: s.query(req.URL.Query())}
+	if len(bytes.TrimSpace(body)) > 0 {
+		if scrubbed, ok := s.json(body); ok {
+			rec.Body = scrubbed
+		}
+	}
+
+	return rec
+}
+
+// Ключ сопоставления запроса: псевдонимы телефонов заменены нулями
+func (r RecordedRequest) key() string {
+	var blind scrubber
+
+	query, _ := url.ParseQuery(r.Query)
+	var body bytes.Buffer
+	if len(r.Body) > 0 {
+		if scrubbed, ok := blind.json(r.Body); ok {
+			body.Write(scrubbed)
+		} else {
+			json.Compact(&body, r.Body)
+		}
+	}
+
+	return r.Method + " " + r.Path + "?" + blind.query(query) + " " + body.String()
+}
+
+// Параметры запроса с упорядоченными ключами и значениями, без apiKey, с псевдонимами телефонов
+func (s *scrubber) query(q url.Values) string {
+	out := url.Values{}
+	for k, vs := range q {
+		if strings.EqualFold(k, "apiKey") {
+			continue
+		}
+		vs = append([]string(nil), vs...)
+		for idx, v := range vs {
+			vs[idx] = s.value(k, v)
+		}
+		sort.Strings(vs)
+		out[k] = vs
+	}
+
+	return out.Encode()
+}
+
+// Замена чувствительных данных в JSON. ok - данные в формате JSON
+func (s *scrubber) json(data []byte) (json.RawMessage, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return nil, false
+	}
+
+	out, err := json.Marshal(s.any("", v))
+	if err != nil {
+		return nil, false
+	}
+
+	return out, true
+}
+
+func (s *scrubber) any(key string, v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, el := range val {
+			val[k] = s.any(k, el)
+		}
+	case []any:
+		for idx, el := range val {
+			val[idx] = s.any(key, el)
+		}
+	case string:
+		return s.value(key, val)
+	}
+
+	return v
+}
+
+func (s *scrubber) value(key, v string) string {
+	lower := strings.ToLower(key)
+	switch {
+	case v == "":
+		return v
+	case sensitiveFields[lower]:
+		return "***"
+	case strings.Contains(lower, "phone"):
+		return s.phone(v)
+	}
+
+	return v
+}
+
+// Псевдоним телефона. Новый телефон получает следующий номер по порядку
+func (s *scrubber) phone(phone string) string {
+	if s.phones == nil {
+		return phoneAlias(phone, 0)
+	}
+
+	alias, ok := s.phones[phone]
+	if !ok {
+		alias = phoneAlias(phone, len(s.phones)+1)
+		s.phones[phone] = alias
+	}
+
+	return alias
+}
+
+// Телефон, цифры которого заменены номером n с ведущими нулями. Префикс +7 и остальные символы сохраняются.
+// Если в номере n больше цифр, чем в телефоне, старшие цифры вставляются перед первой заменённой,
+// чтобы псевдонимы разных телефонов не совпадали
+func phoneAlias(phone string, n int) string {
+	seq := strconv.Itoa(n)
+	out := []byte(phone)
+
+	first := -1
+	for idx := len(out) - 1; idx >= 0; idx-- {
+		if out[idx] < '0' || out[idx] > '9' || (idx == 1 && out[0] == '+') {
+			continue
+		}
+		first = idx
+		out[idx] = '0'
+		if len(seq) > 0 {
+			out[idx] = seq[len(seq)-1]
+			seq = seq[:len(seq)-1]
+		}
+	}
+
+	if first >= 0 && len(seq) > 0 {
+		return string(out[:first]) + seq + string(out[first:])
+	}
+	return string(out)
+}
@@ -0,0 +1,219 @@
+package andromedatest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+const (
+	realPhone  = "+79161234567"
+	otherPhone = "+79167654321"
+)
+
+// Сервер Андромеды с паролем объекта, PIN кодом и телефонами в ответах
+func newSecretServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/Sites":
+			io.WriteString(w, `{"Id":"s1","ObjectPassword":"pass","Phone1":"`+realPhone+`"}`)
+		case "/Customers":
+			io.WriteString(w, `[{"Id":"c1","PINCode":"1234","MobilePhone":"`+otherPhone+`"},{"Id":"c2","MobilePhone":"`+realPhone+`"}]`)
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, "<html>not found</html>")
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func get(t *testing.T, rt http.RoundTripper, url string) (int, string) {
+	t.Helper()
+
+	resp, err := (&http.Client{Transport: rt}).Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	return resp.StatusCode, string(body)
+}
+
+func TestRecorderScrubbing(t *testing.T) {
+	srv := newSecretServer(t)
+	rec := &Recorder{}
+
+	get(t, rec, srv.URL+"/Sites?id=s1&apiKey=secret-key")
+	_, body := get(t, rec, srv.URL+"/Customers?siteId=s1&phone="+strings.ReplaceAll(otherPhone, "+", "%2B"))
+	if !strings.Contains(body, realPhone) {
+		t.Fatalf("записывающий транспорт изменил ответ: %s", body)
+	}
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	if err := rec.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := string(data)
+
+	tests := []struct {
+		name    string
+		value   string
+		present bool
+	}{
+		{name: "API ключ", value: "secret-key"},
+		{name: "пароль объекта", value: "pass"},
+		{name: "PIN код", value: "1234"},
+		{name: "телефон", value: realPhone},
+		{name: "телефон в параметрах", value: "79167654321"},
+		{name: "псевдоним первого телефона", value: "+70000000001", present: true},
+		{name: "псевдоним второго телефона", value: "+70000000002", present: true},
+		{name: "третий псевдоним для повторного телефона", value: "+70000000003"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Contains(saved, tt.value); got != tt.present {
+				t.Fatalf("%q в записи: %v, ожидалось %v\n%s", tt.value, got, tt.present, saved)
+			}
+		})
+	}
+}
+
+func TestReplayer(t *testing.T) {
+	srv := newSecretServer(t)
+	rec := &Recorder{}
+
+	requests := []string{
+		"/Sites?id=s1&apiKey=k1",
+		"/Customers?siteId=s1&phone=%2B79160000000",
+		"/Unknown",
+	}
+	for _, r := range requests {
+		get(t, rec, srv.URL+r)
+	}
+
+	rep := NewReplayer(rec.Cassette())
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		body   string
+		err    bool
+	}{
+		{name: "другой API ключ и порядок параметров", path: "/Sites?apiKey=k2&id=s1", status: http.StatusOK, body: `"ObjectPassword":"***"`},
+		{name: "другой телефон", path: "/Customers?phone=%2B79169999999&siteId=s1", status: http.StatusOK, body: `"PINCode":"***"`},
+		{name: "ответ не в формате JSON", path: "/Unknown", status: http.StatusNotFound, body: "<html>not found</html>"},
+		{name: "повторный запрос", path: "/Sites?id=s1", status: http.StatusOK, body: `"Id":"s1"`},
+		{name: "нет записи", path: "/Sites?id=s2", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := (&http.Client{Transport: rep}).Get("http://andromeda.local" + tt.path)
+			if tt.err {
+				var unmatched *UnmatchedError
+				if !errors.As(err, &unmatched) {
+					t.Fatalf("ошибка %v, ожидалась UnmatchedError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tt.status {
+				t.Fatalf("статус %d, ожидался %d", resp.StatusCode, tt.status)
+			}
+			if !strings.Contains(string(body), tt.body) {
+				t.Fatalf("ответ %s не содержит %s", body, tt.body)
+			}
+		})
+	}
+
+	if unused := rep.Unused(); len(unused) != 0 {
+		t.Fatalf("не использовано записей: %d", len(unused))
+	}
+}
+
+// Запись и воспроизведение через клиент SDK
+func TestUseCassette(t *testing.T) {
+	srv := newSecretServer(t)
+	path := filepath.Join(t.TempDir(), "sites.json")
+	cfg := andromeda.Config{Host: srv.URL, ApiKey: "key"}
+
+	t.Run("запись", func(t *testing.T) {
+		t.Setenv(RecordEnv, "1")
+		c := andromeda.NewClient(andromeda.WithTransport(UseCassette(t, path)))
+		if _, err := c.GetSites(context.Background(), andromeda.GetSitesInput{Id: "s1", Config: cfg}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("воспроизведение", func(t *testing.T) {
+		srv.Close()
+		c := andromeda.NewClient(andromeda.WithTransport(UseCassette(t, path)))
+		site, err := c.GetSites(context.Background(), andromeda.GetSitesInput{Id: "s1", Config: cfg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if site.Id != "s1" || site.ObjectPassword != "***" {
+			t.Fatalf("ответ воспроизведён неверно: %+v", site)
+		}
+	})
+}
+
+func TestLoadCassette(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.json")
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadCassette(path); andromeda.CodeOf(err) != andromeda.CodeCassetteFormat {
+		t.Fatalf("ошибка %v, ожидался код %q", err, andromeda.CodeCassetteFormat)
+	}
+}
+
+func TestPhoneAlias(t *testing.T) {
+	tests := []struct {
+		phone string
+		n     int
+		want  string
+	}{
+		{phone: "+79161234567", n: 1, want: "+70000000001"},
+		{phone: "+7 (916) 123-45-67", n: 12, want: "+7 (000) 000-00-12"},
+		{phone: "89161234567", n: 3, want: "00000000003"},
+		{phone: "+79161234567", n: 0, want: "+70000000000"},
+		{phone: "+7 (916) 12", n: 123456, want: "+7 (1234) 56"},
+		{phone: "+7123", n: 1123, want: "+71123"},
+		{phone: "+7123", n: 2123, want: "+72123"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.phone, func(t *testing.T) {
+			if got := phoneAlias(tt.phone, tt.n); got != tt.want {
+				t.Fatalf("псевдоним %q, ожидался %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+// Пакет andromedatest содержит вспомогательные функции для тестов кода, использующего SDK.
+package andromedatest
+
+import (
+	"os"
+	"testing"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+// Проверка, что записанный ответ сервера соответствует структуре SDK v (например []andromeda.GetZonesResponse{}).
+// Каждое расхождение выводится отдельной ошибкой теста
+func AssertSchema(t testing.TB, data []byte, v any) {
+	t.Helper()
+
+	issues, err := andromeda.DiffSchema(data, v)
+	if err != nil {
+		t.Fatalf("не удалось разобрать ответ: %v", err)
+	}
+	for _, issue := range issues {
+		t.Errorf("%s: %s", issue.Kind, issue.Path)
+	}
+}
+
+// Проверка записанного ответа из файла
+func AssertSchemaFile(t testing.TB, path string, v any) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("не удалось прочитать %s: %v", path, err)
+	}
+	AssertSchema(t, data, v)
+}
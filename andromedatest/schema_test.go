@@ -0,0 +1,76 @@
+package andromedatest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+// testing.TB, запоминающий ошибки вместо завершения теста
+type recordingTB struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Fatalf(format string, args ...any) {
+	r.fatal = true
+	r.Errorf(format, args...)
+}
+
+func TestAssertSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		v      any
+		issues int
+		fatal  bool
+	}{
+		{name: "совпадает", data: `[{"Id":"z1","ZoneNumber":1,"ZoneDesc":"d","ZoneEquip":"e"}]`, v: []andromeda.GetZonesResponse{}},
+		{name: "новое поле", data: `[{"Id":"z1","ZoneNumber":1,"ZoneDesc":"d","ZoneEquip":"e","Extra":1}]`, v: []andromeda.GetZonesResponse{}, issues: 1},
+		{name: "нет поля и другой тип", data: `[{"Id":"z1","ZoneNumber":"1","ZoneDesc":"d"}]`, v: []andromeda.GetZonesResponse{}, issues: 2},
+		{name: "не JSON", data: `<html>`, v: []andromeda.GetZonesResponse{}, fatal: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recordingTB{TB: t}
+			AssertSchema(rec, []byte(tt.data), tt.v)
+
+			if rec.fatal != tt.fatal {
+				t.Fatalf("Fatalf: %v, ожидалось %v (%v)", rec.fatal, tt.fatal, rec.errors)
+			}
+			if !tt.fatal && len(rec.errors) != tt.issues {
+				t.Fatalf("расхождений %d, ожидалось %d: %v", len(rec.errors), tt.issues, rec.errors)
+			}
+		})
+	}
+}
+
+func TestAssertSchemaFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "site.json")
+	if err := os.WriteFile(path, []byte(`{"Id":"s1","NewField":true}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := &recordingTB{TB: t}
+	AssertSchemaFile(rec, path, andromeda.GetSitesResponse{})
+	if rec.fatal || len(rec.errors) == 0 {
+		t.Fatalf("новое поле не найдено: %v", rec.errors)
+	}
+
+	rec = &recordingTB{TB: t}
+	AssertSchemaFile(rec, filepath.Join(t.TempDir(), "missing.json"), andromeda.GetSitesResponse{})
+	if !rec.fatal {
+		t.Fatal("нет ошибки для отсутствующего файла")
+	}
+}
@@ -0,0 +1,119 @@
+// Пакет audit проверяет качество данных в базе объектов Андромеды набором подключаемых правил.
+package audit
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+type (
+	//Важность найденной проблемы
+	Severity string
+
+	//Найденная проблема
+	Finding struct {
+		Rule     string   `json:"rule"`             //Название правила
+		Severity Severity `json:"severity"`         //Важность
+		SiteId   string   `json:"siteId"`           //Идентификатор объекта
+		Target   string   `json:"target,omitempty"` //Идентификатор ответственного, раздела или шлейфа, к которому относится проблема
+		Message  string   `json:"message"`          //Описание проблемы
+		Fix      string   `json:"fix"`              //Рекомендуемое исправление
+	}
+
+	//Данные объекта, по которым выполняются проверки
+	Site = andromeda.SiteData
+
+	//Правило проверки объекта
+	Rule interface {
+		Name() string
+		Check(site Site) []Finding
+	}
+
+	//Методы SDK, которые использует Auditor. Реализуется *andromeda.Client
+	API = andromeda.SiteDataAPI
+
+	//Проверка объектов через SDK
+	Auditor struct {
+		Client        API
+		Config        andromeda.Config
+		UserName      string  //Имя пользователя, от которого делаются запросы (необязательное поле)
+		Rules         []Rule  //Правила проверки, по умолчанию DefaultRules()
+		Concurrency   int     //Количество объектов, загружаемых одновременно
+		RatePerSecond float64 //Ограничение частоты запросов к серверу, 0 - без ограничения
+	}
+
+	//Результат проверки
+	Report struct {
+		GeneratedAt  time.Time         `json:"generatedAt"`
+		SitesChecked int               `json:"sitesChecked"`
+		Findings     []Finding         `json:"findings"`
+		Errors       map[string]string `json:"errors,omitempty"` //Ошибки загрузки по номеру или идентификатору объекта
+	}
+)
+
+// Проверка объектов, заданных номерами или идентификаторами
+func (a *Auditor) Run(ctx context.Context, sites []string) (Report, error) {
+	rules := a.Rules
+	if rules == nil {
+		rules = DefaultRules()
+	}
+
+	results, _ := andromeda.Bulk(ctx, sites, a.fetch, andromeda.BulkOptions{
+		Concurrency:   a.Concurrency,
+		RatePerSecond: a.RatePerSecond,
+	})
+
+	report := Report{GeneratedAt: time.Now(), Findings: []Finding{}, Errors: map[string]string{}}
+	for _, r := range results {
+		if r.Err != nil {
+			report.Errors[r.Input] = r.Err.Error()
+			continue
+		}
+		report.SitesChecked++
+		report.Findings = append(report.Findings, Check(r.Output, rules)...)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// Проверка данных одного объекта набором правил
+func Check(site Site, rules []Rule) []Finding {
+	var findings []Finding
+	for _, rule := range rules {
+		for _, f := range rule.Check(site) {
+			if f.Rule == "" {
+				f.Rule = rule.Name()
+			}
+			if f.SiteId == "" {
+				f.SiteId = site.Site.Id
+			}
+			findings = append(findings, f)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severityRank[findings[i].Severity] > severityRank[findings[j].Severity]
+	})
+
+	return findings
+}
+
+var severityRank = map[Severity]int{SeverityInfo: 0, SeverityWarning: 1, SeverityError: 2}
+
+// Загрузка всех данных объекта через SDK
+func (a *Auditor) fetch(ctx context.Context, query string) (Site, error) {
+	return andromeda.FetchSiteData(ctx, a.Client, andromeda.GetSitesInput{Id: query, UserName: a.UserName, Config: a.Config})
+}
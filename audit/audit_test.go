@@ -0,0 +1,223 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+// Андромеда в памяти: данные объектов по номеру
+type fakeAPI struct {
+	sites map[string]Site
+}
+
+func (f *fakeAPI) GetSites(ctx context.Context, in andromeda.GetSitesInput) (andromeda.GetSitesResponse, error) {
+	s, ok := f.sites[in.Id]
+	if !ok {
+		return andromeda.GetSitesResponse{}, errors.New("объект не найден")
+	}
+	return s.Site, nil
+}
+
+func (f *fakeAPI) site(id string) Site {
+	for _, s := range f.sites {
+		if s.Site.Id == id {
+			return s
+		}
+	}
+	return Site{}
+}
+
+func (f *fakeAPI) Customers(ctx context.Context, in andromeda.GetCustomersInput) ([]andromeda.GetCustomerResponse, error) {
+	return f.site(in.SiteId).Customers, nil
+}
+
+func (f *fakeAPI) GetParts(ctx context.Context, in andromeda.GetPartsInput) ([]andromeda.GetPartsResponse, error) {
+	return f.site(in.SiteId).Parts, nil
+}
+
+func (f *fakeAPI) GetZones(ctx context.Context, in andromeda.GetZonesInput) ([]andromeda.GetZonesResponse, error) {
+	return f.site(in.SiteId).Zones, nil
+}
+
+func (f *fakeAPI) GetUsersMyAlarm(ctx context.Context, in andromeda.GetUsersMyAlarmInput) ([]andromeda.UserMyAlarmResponse, error) {
+	return f.site(in.SiteId).Users, nil
+}
+
+// Объект без проблем
+func goodSite() Site {
+	return Site{
+		Site:      andromeda.GetSitesResponse{Id: "s1", AccountNumber: 101, Name: "Склад"},
+		Customers: []andromeda.GetCustomerResponse{{Id: "c1", ObjCustName: "Иванов", ObjCustPhone1: "+79001234567", IsVisibleInCabinet: true}},
+		Parts:     []andromeda.GetPartsResponse{{Id: "p1", PartNumber: 1, PartDesc: "Склад"}},
+		Zones:     []andromeda.GetZonesResponse{{Id: "z1", ZoneNumber: 1, ZoneDesc: "Дверь"}},
+		Users:     []andromeda.UserMyAlarmResponse{{CustomerID: "c1", MyAlarmPhone: "+79001234567"}},
+	}
+}
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		edit    func(s *Site)
+		targets []string
+	}{
+		{name: "объект без проблем", rule: SiteWithoutCustomers, edit: func(s *Site) {}},
+		{
+			name:    "нет ответственных",
+			rule:    SiteWithoutCustomers,
+			edit:    func(s *Site) { s.Customers = nil },
+			targets: []string{""},
+		},
+		{
+			name: "одинаковые телефоны в разном формате",
+			rule: DuplicateCustomerPhones,
+			edit: func(s *Site) {
+				s.Customers = append(s.Customers, andromeda.GetCustomerResponse{Id: "c2", ObjCustPhone1: "+7 (900) 123-45-67"})
+			},
+			targets: []string{"c2"},
+		},
+		{
+			name: "пустые телефоны не считаются дубликатами",
+			rule: DuplicateCustomerPhones,
+			edit: func(s *Site) {
+				s.Customers = append(s.Customers, andromeda.GetCustomerResponse{Id: "c2"}, andromeda.GetCustomerResponse{Id: "c3"})
+			},
+		},
+		{
+			name:    "нет мобильного телефона",
+			rule:    CustomerWithoutPhone,
+			edit:    func(s *Site) { s.Customers[0].ObjCustPhone1 = " - " },
+			targets: []string{"c1"},
+		},
+		{
+			name:    "раздел без описания",
+			rule:    PartWithoutDescription,
+			edit:    func(s *Site) { s.Parts[0].PartDesc = "  " },
+			targets: []string{"p1"},
+		},
+		{
+			name:    "шлейф без описания",
+			rule:    ZoneWithoutDescription,
+			edit:    func(s *Site) { s.Zones[0].ZoneDesc = "" },
+			targets: []string{"z1"},
+		},
+		{
+			name:    "пользователь MyAlarm скрыт в личном кабинете",
+			rule:    MyAlarmUserNotVisible,
+			edit:    func(s *Site) { s.Customers[0].IsVisibleInCabinet = false },
+			targets: []string{"c1"},
+		},
+		{
+			name:    "пользователь MyAlarm без ответственного",
+			rule:    MyAlarmUserNotVisible,
+			edit:    func(s *Site) { s.Users[0].CustomerID = "c9" },
+			targets: []string{"c9"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site := goodSite()
+			tt.edit(&site)
+
+			findings := Check(site, []Rule{tt.rule})
+			if len(findings) != len(tt.targets) {
+				t.Fatalf("проблем %d, ожидалось %d: %+v", len(findings), len(tt.targets), findings)
+			}
+			for idx, f := range findings {
+				if f.Target != tt.targets[idx] || f.Rule != tt.rule.Name() || f.SiteId != "s1" {
+					t.Errorf("проблема %+v, ожидался элемент %q", f, tt.targets[idx])
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultRulesGoodSite(t *testing.T) {
+	if findings := Check(goodSite(), DefaultRules()); len(findings) != 0 {
+		t.Fatalf("найдены проблемы у объекта без проблем: %+v", findings)
+	}
+}
+
+func TestCheckOrder(t *testing.T) {
+	site := goodSite()
+	site.Customers[0].ObjCustPhone1 = ""
+	site.Customers[0].IsVisibleInCabinet = false
+	site.Zones[0].ZoneDesc = ""
+
+	findings := Check(site, DefaultRules())
+	var got []Severity
+	for _, f := range findings {
+		got = append(got, f.Severity)
+	}
+	want := []Severity{SeverityError, SeverityWarning, SeverityInfo}
+	if len(got) != len(want) {
+		t.Fatalf("важность %v, ожидалось %v", got, want)
+	}
+	for idx := range want {
+		if got[idx] != want[idx] {
+			t.Fatalf("важность %v, ожидалось %v", got, want)
+		}
+	}
+}
+
+func TestAuditorRun(t *testing.T) {
+	empty := goodSite()
+	empty.Site = andromeda.GetSitesResponse{Id: "s2", AccountNumber: 102}
+	empty.Customers, empty.Users = nil, nil
+
+	api := &fakeAPI{sites: map[string]Site{"101": goodSite(), "102": empty}}
+	a := &Auditor{Client: api, Rules: []Rule{SiteWithoutCustomers}}
+
+	report, err := a.Run(context.Background(), []string{"101", "102", "103"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.SitesChecked != 2 {
+		t.Errorf("проверено объектов %d, ожидалось 2", report.SitesChecked)
+	}
+	if len(report.Findings) != 1 || report.Findings[0].SiteId != "s2" {
+		t.Errorf("проблемы %+v, ожидалась одна у объекта s2", report.Findings)
+	}
+	if _, ok := report.Errors["103"]; !ok || len(report.Errors) != 1 {
+		t.Errorf("ошибки загрузки %v, ожидалась ошибка объекта 103", report.Errors)
+	}
+}
+
+func TestReportWrite(t *testing.T) {
+	report := Report{
+		SitesChecked: 1,
+		Findings:     []Finding{{Rule: "r", Severity: SeverityError, SiteId: "s1", Message: "<script>"}},
+		Errors:       map[string]string{"103": "объект не найден"},
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Findings) != 1 || decoded.Errors["103"] == "" {
+		t.Fatalf("отчёт JSON %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := report.WriteHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+	if strings.Contains(html, "<script>") || !strings.Contains(html, "&lt;script&gt;") {
+		t.Fatal("описание проблемы не экранировано в HTML")
+	}
+	if !strings.Contains(html, "объект не найден") {
+		t.Fatal("ошибки загрузки не выведены в HTML")
+	}
+}
@@ -0,0 +1,60 @@
+package audit
+
+import (
+	"encoding/json"
+	"html/template"
+	"io"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>Проверка качества данных</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+.error { background: #fdd; }
+.warning { background: #ffd; }
+.info { background: #eef; }
+</style>
+</head>
+<body>
+<h1>Проверка качества данных</h1>
+<p>Сформирован: {{.GeneratedAt.Format "02.01.2006 15:04:05"}}. Проверено объектов: {{.SitesChecked}}. Найдено проблем: {{len .Findings}}.</p>
+{{if .Errors}}<h2>Ошибки загрузки</h2>
+<table>
+<tr><th>Объект</th><th>Ошибка</th></tr>
+{{range $site, $err := .Errors}}<tr><td>{{$site}}</td><td>{{$err}}</td></tr>
+{{end}}</table>
+{{end}}<h2>Проблемы</h2>
+<table>
+<tr><th>Важность</th><th>Правило</th><th>Объект</th><th>Элемент</th><th>Описание</th><th>Исправление</th></tr>
+{{range .Findings}}<tr class="{{.Severity}}"><td>{{.Severity}}</td><td>{{.Rule}}</td><td>{{.SiteId}}</td><td>{{.Target}}</td><td>{{.Message}}</td><td>{{.Fix}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// Вывод отчёта в формате JSON
+func (r Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return &andromeda.Error{Code: andromeda.CodeReportWrite, Err: err}
+	}
+
+	return nil
+}
+
+// Вывод отчёта в формате HTML
+func (r Report) WriteHTML(w io.Writer) error {
+	if err := reportTemplate.Execute(w, r); err != nil {
+		return &andromeda.Error{Code: andromeda.CodeReportWrite, Err: err}
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+// Правило, заданное функцией
+type RuleFunc struct {
+	RuleName string
+	Func     func(site Site) []Finding
+}
+
+func (r RuleFunc) Name() string {
+	return r.RuleName
+}
+
+func (r RuleFunc) Check(site Site) []Finding {
+	return r.Func(site)
+}
+
+// Стандартный набор правил
+func DefaultRules() []Rule {
+	return []Rule{
+		SiteWithoutCustomers,
+		DuplicateCustomerPhones,
+		CustomerWithoutPhone,
+		PartWithoutDescription,
+		ZoneWithoutDescription,
+		MyAlarmUserNotVisible,
+	}
+}
+
+// Объект без ответственных лиц
+var SiteWithoutCustomers = RuleFunc{RuleName: "site-without-customers", Func: func(s Site) []Finding {
+	if len(s.Customers) > 0 {
+		return nil
+	}
+
+	return []Finding{{
+		Severity: SeverityError,
+		Message:  fmt.Sprintf("у объекта %d «%s» нет ответственных лиц", s.Site.AccountNumber, s.Site.Name),
+		Fix:      "добавить ответственных лиц в карточку объекта",
+	}}
+}}
+
+// Ответственные лица объекта с одинаковым мобильным телефоном
+var DuplicateCustomerPhones = RuleFunc{RuleName: "duplicate-customer-phones", Func: func(s Site) []Finding {
+	byPhone := map[string][]andromeda.GetCustomerResponse{}
+	var phones []string
+	for _, c := range s.Customers {
+		phone := normalizePhone(c.ObjCustPhone1)
+		if phone == "" {
+			continue
+		}
+		if _, ok := byPhone[phone]; !ok {
+			phones = append(phones, phone)
+		}
+		byPhone[phone] = append(byPhone[phone], c)
+	}
+
+	var findings []Finding
+	for _, phone := range phones {
+		dups := byPhone[phone]
+		if len(dups) < 2 {
+			continue
+		}
+		names := make([]string, len(dups))
+		for idx, c := range dups {
+			names[idx] = c.ObjCustName
+		}
+		findings = append(findings, Finding{
+			Severity: SeverityWarning,
+			Target:   dups[1].Id,
+			Message:  fmt.Sprintf("телефон %s указан у нескольких ответственных: %s", phone, strings.Join(names, ", ")),
+			Fix:      "удалить дубликаты ответственного или исправить телефон",
+		})
+	}
+
+	return findings
+}}
+
+// Ответственное лицо без мобильного телефона
+var CustomerWithoutPhone = RuleFunc{RuleName: "customer-without-phone", Func: func(s Site) []Finding {
+	var findings []Finding
+	for _, c := range s.Customers {
+		if normalizePhone(c.ObjCustPhone1) != "" {
+			continue
+		}
+		findings = append(findings, Finding{
+			Severity: SeverityInfo,
+			Target:   c.Id,
+			Message:  fmt.Sprintf("у ответственного «%s» не задан мобильный телефон", c.ObjCustName),
+			Fix:      "заполнить мобильный телефон ответственного",
+		})
+	}
+
+	return findings
+}}
+
+// Раздел без описания
+var PartWithoutDescription = RuleFunc{RuleName: "part-without-description", Func: func(s Site) []Finding {
+	var findings []Finding
+	for _, p := range s.Parts {
+		if strings.TrimSpace(p.PartDesc) != "" {
+			continue
+		}
+		findings = append(findings, Finding{
+			Severity: SeverityWarning,
+			Target:   p.Id,
+			Message:  fmt.Sprintf("у раздела %d нет описания", p.PartNumber),
+			Fix:      "заполнить описание раздела",
+		})
+	}
+
+	return findings
+}}
+
+// Шлейф без описания
+var ZoneWithoutDescription = RuleFunc{RuleName: "zone-without-description", Func: func(s Site) []Finding {
+	var findings []Finding
+	for _, z := range s.Zones {
+		if strings.TrimSpace(z.ZoneDesc) != "" {
+			continue
+		}
+		findings = append(findings, Finding{
+			Severity: SeverityWarning,
+			Target:   z.Id,
+			Message:  fmt.Sprintf("у шлейфа %d нет описания", z.ZoneNumber),
+			Fix:      "заполнить описание шлейфа",
+		})
+	}
+
+	return findings
+}}
+
+// Пользователь MyAlarm, ответственный которого не отображается в личном кабинете
+var MyAlarmUserNotVisible = RuleFunc{RuleName: "myalarm-user-not-visible", Func: func(s Site) []Finding {
+	customers := make(map[string]andromeda.GetCustomerResponse, len(s.Customers))
+	for _, c := range s.Customers {
+		customers[c.Id] = c
+	}
+
+	var findings []Finding
+	for _, u := range s.Users {
+		c, ok := customers[u.CustomerID]
+		if !ok {
+			findings = append(findings, Finding{
+				Severity: SeverityError,
+				Target:   u.CustomerID,
+				Message:  fmt.Sprintf("пользователь MyAlarm %s не найден среди ответственных объекта", u.MyAlarmPhone),
+				Fix:      "отвязать пользователя MyAlarm или восстановить ответственного",
+			})
+			continue
+		}
+		if !c.IsVisibleInCabinet {
+			findings = append(findings, Finding{
+				Severity: SeverityError,
+				Target:   u.CustomerID,
+				Message:  fmt.Sprintf("у пользователя MyAlarm «%s» отключено отображение в личном кабинете", c.ObjCustName),
+				Fix:      "включить признак IsVisibleInCabinet у ответственного",
+			})
+		}
+	}
+
+	return findings
+}}
+
+// Телефон без пробелов, скобок и дефисов
+func normalizePhone(phone string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '+' || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, phone)
+}
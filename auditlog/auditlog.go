@@ -0,0 +1,238 @@
+// Пакет auditlog хранит журнал изменяющих запросов SDK в файле JSON Lines с цепочкой хешей:
+// каждая запись содержит хеш предыдущей.
+//
+// Проверка цепочки обнаруживает изменение любой записи, удаление, вставку и перестановку записей в начале
+// и в середине журнала. Без ключа хеш - это SHA-256, и тот, кто может писать в файл, может пересчитать
+// цепочку после изменённой записи; с ключом HMAC (Open, VerifyOptions.Key) это невозможно без ключа.
+// Удаление последних записей цепочка не обнаруживает: для этого хеш последней записи (FileSink.Head)
+// нужно сохранять вне журнала и передавать в VerifyOptions.Head.
+package auditlog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+const maxLineSize = 1 << 20
+
+type (
+	//Журнал в файле JSON Lines. Реализует andromeda.AuditSink
+	FileSink struct {
+		Sync bool //Сбрасывать файл на диск после каждой записи
+
+		mu   sync.Mutex
+		file *os.File
+		key  []byte
+		last string //Хеш последней записи
+	}
+
+	//Параметры проверки журнала
+	VerifyOptions struct {
+		Key  []byte //Ключ HMAC, с которым записан журнал; nil - журнал без ключа
+		Head string //Хеш последней записи, сохранённый вне журнала (FileSink.Head); пусто - не проверяется
+	}
+
+	//Нарушение цепочки хешей
+	ChainError struct {
+		Line int                 //Номер строки файла, начиная с 1
+		Code andromeda.ErrorCode //Причина: CodeAuditPrevHash, CodeAuditRecordHash, CodeAuditRecordFormat или CodeAuditHeadHash
+		Lang andromeda.Lang      //Язык сообщения
+	}
+)
+
+func (e *ChainError) Error() string {
+	return andromeda.CodeAuditTampered.Message(e.Lang) + " " + strconv.Itoa(e.Line) + ": " + e.Code.Message(e.Lang)
+}
+
+// Причина нарушения как ошибка SDK: andromeda.CodeOf возвращает её код
+func (e *ChainError) Unwrap() error {
+	return &andromeda.Error{Code: e.Code, Lang: e.Lang}
+}
+
+// Ошибка на языке lang
+func (e *ChainError) Localize(lang andromeda.Lang) error {
+	return &ChainError{Line: e.Line, Code: e.Code, Lang: lang}
+}
+
+// Открытие (создание) журнала. key - ключ HMAC для хешей записей, nil - SHA-256 без ключа.
+// Цепочка продолжается от последней записи файла; существующие записи проверяются с тем же ключом
+func Open(path string, key []byte) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, &andromeda.Error{Code: andromeda.CodeAuditLogOpen, Detail: path, Err: err}
+	}
+
+	last, err := verify(file, VerifyOptions{Key: key})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &FileSink{file: file, key: key, last: last}, nil
+}
+
+// Добавление записи: заполняются PrevHash и Hash
+func (s *FileSink) Record(_ context.Context, rec andromeda.AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return &andromeda.Error{Code: andromeda.CodeAuditLogClosed}
+	}
+
+	rec.PrevHash = s.last
+	hash, err := Hash(rec, s.key)
+	if err != nil {
+		return err
+	}
+	rec.Hash = hash
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return &andromeda.Error{Code: andromeda.CodeAuditLogWrite, Err: err}
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return &andromeda.Error{Code: andromeda.CodeAuditLogWrite, Err: err}
+	}
+	if s.Sync {
+		if err := s.file.Sync(); err != nil {
+			return &andromeda.Error{Code: andromeda.CodeAuditLogWrite, Err: err}
+		}
+	}
+	s.last = hash
+
+	return nil
+}
+
+// Хеш последней записи журнала для проверки через VerifyOptions.Head
+func (s *FileSink) Head() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+
+	return err
+}
+
+// Хеш записи: HMAC-SHA256 с ключом key (SHA-256, если key пустой) от JSON записи с пустым полем Hash.
+// PrevHash входит в хеш и связывает записи в цепочку
+func Hash(rec andromeda.AuditRecord, key []byte) (string, error) {
+	rec.Hash = ""
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return "", &andromeda.Error{Code: andromeda.CodeAuditHash, Err: err}
+	}
+
+	if len(key) == 0 {
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:]), nil
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Проверка цепочки хешей журнала. Возвращает ChainError для первой нарушенной записи
+// или, если последняя запись не совпала с opts.Head, для строки после последней записи
+func Verify(r io.Reader, opts VerifyOptions) error {
+	_, err := verify(r, opts)
+	return err
+}
+
+// Проверка цепочки хешей файла журнала
+func VerifyFile(path string, opts VerifyOptions) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return &andromeda.Error{Code: andromeda.CodeAuditLogOpen, Detail: path, Err: err}
+	}
+	defer file.Close()
+
+	return Verify(file, opts)
+}
+
+// Чтение записей журнала
+func Read(r io.Reader) ([]andromeda.AuditRecord, error) {
+	var records []andromeda.AuditRecord
+	_, err := scan(r, func(_ int, rec andromeda.AuditRecord) error {
+		records = append(records, rec)
+		return nil
+	})
+
+	return records, err
+}
+
+// Проверка цепочки; возвращает хеш последней записи
+func verify(r io.Reader, opts VerifyOptions) (string, error) {
+	last := ""
+	lines, err := scan(r, func(line int, rec andromeda.AuditRecord) error {
+		if rec.PrevHash != last {
+			return &ChainError{Line: line, Code: andromeda.CodeAuditPrevHash}
+		}
+		hash, err := Hash(rec, opts.Key)
+		if err != nil {
+			return err
+		}
+		if !hmac.Equal([]byte(hash), []byte(rec.Hash)) {
+			return &ChainError{Line: line, Code: andromeda.CodeAuditRecordHash}
+		}
+		last = hash
+		return nil
+	})
+	if err != nil {
+		return last, err
+	}
+	if opts.Head != "" && opts.Head != last {
+		return last, &ChainError{Line: lines + 1, Code: andromeda.CodeAuditHeadHash}
+	}
+
+	return last, nil
+}
+
+// Чтение записей; возвращает количество прочитанных строк
+func scan(r io.Reader, fn func(line int, rec andromeda.AuditRecord) error) (int, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64<<10), maxLineSize)
+
+	line := 0
+	for sc.Scan() {
+		line++
+		data := bytes.TrimSpace(sc.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var rec andromeda.AuditRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return line, &ChainError{Line: line, Code: andromeda.CodeAuditRecordFormat}
+		}
+		if err := fn(line, rec); err != nil {
+			return line, err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return line, &andromeda.Error{Code: andromeda.CodeAuditLogRead, Err: err}
+	}
+
+	return line, nil
+}
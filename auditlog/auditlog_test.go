@@ -0,0 +1,181 @@
+package auditlog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+var testKey = []byte("test-key")
+
+func testRecord(n int) andromeda.AuditRecord {
+	return andromeda.AuditRecord{
+		Time:     time.Date(2026, 10, 18, 12, 0, n, 0, time.UTC),
+		UserName: "operator",
+		Method:   "PutChangeUserMyAlarm",
+		Host:     "https://andromeda.local",
+		SiteId:   "s1",
+		Params:   map[string]string{"role": strings.Repeat("x", n)},
+		Result:   andromeda.AuditResultOK,
+	}
+}
+
+// Журнал из трёх записей; возвращает строки файла и хеш последней записи
+func writeLog(t *testing.T, key []byte) ([]string, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := Open(path, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n := range 3 {
+		if err := sink.Record(context.Background(), testRecord(n)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"), sink.Head()
+}
+
+// Изменение поля записи с пересчётом цепочки хешей без ключа от строки from
+func rehash(t *testing.T, lines []string, from int, edit func(*andromeda.AuditRecord)) []string {
+	t.Helper()
+
+	out := append([]string(nil), lines...)
+	prev := ""
+	for idx, line := range out {
+		var rec andromeda.AuditRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatal(err)
+		}
+		if idx == from {
+			edit(&rec)
+		}
+		if idx >= from {
+			rec.PrevHash = prev
+			rec.Hash, _ = Hash(rec, nil)
+			data, _ := json.Marshal(rec)
+			out[idx] = string(data)
+		}
+		prev = rec.Hash
+	}
+
+	return out
+}
+
+func TestVerify(t *testing.T) {
+	lines, head := writeLog(t, testKey)
+	edit := func(rec *andromeda.AuditRecord) { rec.SiteId = "s2" }
+
+	tests := []struct {
+		name  string
+		lines []string
+		opts  VerifyOptions
+		line  int
+		code  andromeda.ErrorCode
+	}{
+		{name: "журнал не изменён", lines: lines, opts: VerifyOptions{Key: testKey, Head: head}},
+		{name: "изменено поле записи", lines: []string{lines[0], strings.Replace(lines[1], `"s1"`, `"s2"`, 1), lines[2]}, opts: VerifyOptions{Key: testKey}, line: 2, code: andromeda.CodeAuditRecordHash},
+		{name: "удалена запись в середине", lines: []string{lines[0], lines[2]}, opts: VerifyOptions{Key: testKey}, line: 2, code: andromeda.CodeAuditPrevHash},
+		{name: "удалена первая запись", lines: lines[1:], opts: VerifyOptions{Key: testKey}, line: 1, code: andromeda.CodeAuditPrevHash},
+		{name: "записи переставлены", lines: []string{lines[0], lines[2], lines[1]}, opts: VerifyOptions{Key: testKey}, line: 2, code: andromeda.CodeAuditPrevHash},
+		{name: "запись не в формате JSON", lines: []string{lines[0], "{", lines[2]}, opts: VerifyOptions{Key: testKey}, line: 2, code: andromeda.CodeAuditRecordFormat},
+		{name: "цепочка пересчитана без ключа", lines: rehash(t, lines, 1, edit), opts: VerifyOptions{Key: testKey}, line: 2, code: andromeda.CodeAuditRecordHash},
+		{name: "неверный ключ", lines: lines, opts: VerifyOptions{Key: []byte("other")}, line: 1, code: andromeda.CodeAuditRecordHash},
+		{name: "удалена последняя запись, хеш последней записи сохранён", lines: lines[:2], opts: VerifyOptions{Key: testKey, Head: head}, line: 3, code: andromeda.CodeAuditHeadHash},
+		{name: "удалена последняя запись без сохранённого хеша не обнаруживается", lines: lines[:2], opts: VerifyOptions{Key: testKey}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(strings.NewReader(strings.Join(tt.lines, "\n")), tt.opts)
+			if tt.code == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			var chainErr *ChainError
+			if !errors.As(err, &chainErr) {
+				t.Fatalf("ошибка %v, ожидалась ChainError", err)
+			}
+			if chainErr.Line != tt.line || andromeda.CodeOf(err) != tt.code {
+				t.Fatalf("строка %d, код %q, ожидались %d и %q", chainErr.Line, andromeda.CodeOf(err), tt.line, tt.code)
+			}
+		})
+	}
+}
+
+// Журнал без ключа можно переписать целиком: это обнаруживает только сохранённый хеш последней записи
+func TestVerifyWithoutKey(t *testing.T) {
+	lines, head := writeLog(t, nil)
+	forged := rehash(t, lines, 1, func(rec *andromeda.AuditRecord) { rec.SiteId = "s2" })
+
+	if err := Verify(strings.NewReader(strings.Join(forged, "\n")), VerifyOptions{}); err != nil {
+		t.Fatalf("ожидалось, что без ключа подмена не обнаруживается: %v", err)
+	}
+	if err := Verify(strings.NewReader(strings.Join(forged, "\n")), VerifyOptions{Head: head}); andromeda.CodeOf(err) != andromeda.CodeAuditHeadHash {
+		t.Fatalf("ошибка %v, ожидался код %q", err, andromeda.CodeAuditHeadHash)
+	}
+}
+
+func TestFileSinkReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	for n := range 2 {
+		sink, err := Open(path, testKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sink.Record(context.Background(), testRecord(n)); err != nil {
+			t.Fatal(err)
+		}
+		head := sink.Head()
+		sink.Close()
+
+		if err := VerifyFile(path, VerifyOptions{Key: testKey, Head: head}); err != nil {
+			t.Fatalf("после записи %d: %v", n+1, err)
+		}
+	}
+
+	if _, err := Open(path, []byte("other")); andromeda.CodeOf(err) != andromeda.CodeAuditRecordHash {
+		t.Fatalf("открытие с другим ключом: %v", err)
+	}
+
+	sink, err := Open(path, testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.Close()
+	if err := sink.Record(context.Background(), testRecord(3)); andromeda.CodeOf(err) != andromeda.CodeAuditLogClosed {
+		t.Fatalf("запись в закрытый журнал: %v", err)
+	}
+}
+
+func TestChainErrorLocalize(t *testing.T) {
+	err := andromeda.Localize(&ChainError{Line: 7, Code: andromeda.CodeAuditPrevHash}, andromeda.LangEN)
+
+	if got := err.Error(); got != "audit log tampered: line 7: previous record hash mismatch" {
+		t.Fatalf("сообщение %q", got)
+	}
+	if !errors.Is(err, &andromeda.Error{Code: andromeda.CodeAuditPrevHash}) {
+		t.Fatal("после перевода потерян код причины")
+	}
+}
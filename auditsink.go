@@ -0,0 +1,86 @@
+package andromeda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"time"
+)
+
+const (
+	AuditResultOK    = "ok"
+	AuditResultError = "error"
+)
+
+type (
+	//Запись журнала изменяющих запросов (PostCheckPanic, PutChangeUserMyAlarm, PutChangeKTSUserMyAlarm)
+	AuditRecord struct {
+		Time         time.Time         `json:"time"`
+		UserName     string            `json:"userName,omitempty"`   //Пользователь, от которого выполнен запрос
+		Method       string            `json:"method"`               //Метод SDK
+		Host         string            `json:"host"`                 //Адрес сервера
+		SiteId       string            `json:"siteId,omitempty"`     //Объект, к которому относится изменение
+		CustomerId   string            `json:"customerId,omitempty"` //Ответственное лицо, к которому относится изменение
+		Params       map[string]string `json:"params,omitempty"`     //Параметры запроса
+		Result       string            `json:"result"`               //AuditResultOK или AuditResultError
+		Error        string            `json:"error,omitempty"`
+		SpResultCode int               `json:"spResultCode,omitempty"` //Код результата сервера при ошибке 400
+		Response     json.RawMessage   `json:"response,omitempty"`     //Ответ сервера
+		DurationMs   int64             `json:"durationMs"`
+
+		PrevHash string `json:"prevHash,omitempty"` //Хеш предыдущей записи (заполняется журналом с цепочкой хешей)
+		Hash     string `json:"hash,omitempty"`     //Хеш записи
+	}
+
+	//Журнал изменяющих запросов
+	AuditSink interface {
+		Record(ctx context.Context, rec AuditRecord) error
+	}
+)
+
+// Запись изменяющих запросов в журнал sink. Ошибка записи в журнал не меняет результат запроса,
+// так как он уже выполнен на сервере; она передаётся в onError (необязательный параметр)
+func WithAuditSink(sink AuditSink, onError func(AuditRecord, error)) Option {
+	return func(c *Client) {
+		c.audit = sink
+		c.auditError = onError
+	}
+}
+
+// Запись в журнал результата изменяющего запроса. Отмена ctx не прерывает запись: запрос уже выполнен
+func (c *Client) recordAudit(ctx context.Context, rec AuditRecord, r request, start time.Time, out any, err error) {
+	rec.Time = start
+	rec.Method = r.name
+	rec.Host = r.host
+	rec.DurationMs = time.Since(start).Milliseconds()
+
+	if u, perr := url.Parse(r.URL); perr == nil {
+		for k, v := range u.Query() {
+			if rec.Params == nil {
+				rec.Params = map[string]string{}
+			}
+			rec.Params[k] = v[0]
+		}
+	}
+
+	if err != nil {
+		rec.Result = AuditResultError
+		rec.Error = err.Error()
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			rec.SpResultCode = apiErr.SpResultCode
+		}
+	} else {
+		rec.Result = AuditResultOK
+		if out != nil {
+			if data, merr := json.Marshal(out); merr == nil {
+				rec.Response = data
+			}
+		}
+	}
+
+	if serr := c.audit.Record(context.WithoutCancel(ctx), rec); serr != nil && c.auditError != nil {
+		c.auditError(rec, serr)
+	}
+}
@@ -0,0 +1,299 @@
+// Пакет billing формирует отчёт о должниках и платежах по финансовым полям карточек объектов.
+package billing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+const (
+	GroupByType     = "type"
+	GroupByContract = "contract"
+
+	LangRU = "ru"
+	LangEN = "en"
+
+	defaultUpcomingDays = 7
+)
+
+type (
+	//Параметры отчёта
+	Options struct {
+		Now          time.Time //Момент формирования отчёта, по умолчанию текущее время
+		UpcomingDays int       //Горизонт ближайших списаний в днях, включая сегодняшний, по умолчанию 7
+		GroupBy      string    //Группировка: GroupByType (по умолчанию) или GroupByContract
+		Lang         string    //Язык текстового отчёта: LangRU (по умолчанию) или LangEN
+		//Ожидаемый уровень информирования о долге для объекта. По умолчанию: больше нуля при отрицательном балансе, иначе ноль
+		ExpectedDebtLevel func(site Site) int
+	}
+
+	//Методы SDK, которые использует Fetch. Реализуется *andromeda.Client
+	API interface {
+		GetSites(ctx context.Context, input andromeda.GetSitesInput) (andromeda.GetSitesResponse, error)
+	}
+
+	//Финансовые данные объекта
+	Site struct {
+		Id              string          `json:"id"`
+		AccountNumber   int             `json:"accountNumber"`
+		Name            string          `json:"name"`
+		TypeName        string          `json:"typeName"`
+		ContractNumber  string          `json:"contractNumber"`
+		ContractPrice   andromeda.Money `json:"contractPrice"`
+		MoneyBalance    andromeda.Money `json:"moneyBalance"`
+		PaymentDate     time.Time       `json:"paymentDate"` //Нулевое значение, если дата не задана или не распознана
+		DebtInformLevel int             `json:"debtInformLevel"`
+	}
+
+	//Итоги по группе объектов
+	Group struct {
+		Key           string          `json:"key"`
+		Sites         int             `json:"sites"`
+		Debtors       int             `json:"debtors"`
+		ContractPrice andromeda.Money `json:"contractPrice"`
+		MoneyBalance  andromeda.Money `json:"moneyBalance"`
+		Debt          andromeda.Money `json:"debt"`
+	}
+
+	//Отчёт по должникам и платежам
+	Report struct {
+		GeneratedAt         time.Time `json:"generatedAt"`
+		GroupBy             string    `json:"groupBy"`
+		Lang                string    `json:"lang,omitempty"` //Язык текстового отчёта
+		Groups              []Group   `json:"groups"`
+		Total               Group     `json:"total"`               //Итоги по всем объектам; Key не заполняется
+		Debtors             []Site    `json:"debtors"`             //Объекты с отрицательным балансом
+		UpcomingPayments    []Site    `json:"upcomingPayments"`    //Объекты с ближайшей датой списания
+		DebtLevelMismatches []Site    `json:"debtLevelMismatches"` //Объекты с неверным уровнем информирования о долге
+	}
+
+	//Заголовки и форматы текстового отчёта на одном языке
+	textLabels struct {
+		siteType, contract, total                    string
+		sites, debtors, contractPrice, balance, debt string
+		debtorsTitle, upcomingTitle, mismatchTitle   string
+		number, name, paymentDate, debtLevel         string
+		dateFormat                                   string
+		money                                        func(andromeda.Money) string
+	}
+)
+
+var (
+	labelsRU = textLabels{
+		siteType: "Тип объекта", contract: "Договор", total: "Итого",
+		sites: "Объектов", debtors: "Должников", contractPrice: "Платёж в месяц", balance: "Баланс", debt: "Долг",
+		debtorsTitle: "Должники", upcomingTitle: "Ближайшие списания", mismatchTitle: "Неверный уровень информирования о долге",
+		number: "Номер", name: "Название", paymentDate: "Дата списания", debtLevel: "Уровень информирования",
+		dateFormat: "02.01.2006",
+		money:      andromeda.Money.String,
+	}
+
+	labelsEN = textLabels{
+		siteType: "Site type", contract: "Contract", total: "Total",
+		sites: "Sites", debtors: "Debtors", contractPrice: "Monthly payment", balance: "Balance", debt: "Debt",
+		debtorsTitle: "Debtors", upcomingTitle: "Upcoming payments", mismatchTitle: "Wrong debt inform level",
+		number: "Number", name: "Name", paymentDate: "Payment date", debtLevel: "Debt inform level",
+		dateFormat: "2006-01-02",
+		money:      andromeda.Money.Decimal,
+	}
+)
+
+func labelsFor(lang string) textLabels {
+	if lang == LangEN {
+		return labelsEN
+	}
+	return labelsRU
+}
+
+// Финансовые данные из карточки объекта
+func FromSite(s andromeda.GetSitesResponse) Site {
+	site := Site{
+		Id:              s.Id,
+		AccountNumber:   s.AccountNumber,
+		Name:            s.Name,
+		TypeName:        s.TypeName,
+		ContractNumber:  s.ContractNumber,
+		ContractPrice:   s.ContractPrice,
+		MoneyBalance:    s.MoneyBalance,
+		DebtInformLevel: s.DebtInformLevel,
+	}
+
+	site.PaymentDate, _ = andromeda.ParseDate(s.PaymentDate)
+
+	return site
+}
+
+// Уровень информирования о долге по умолчанию
+func defaultDebtLevel(site Site) int {
+	if site.MoneyBalance < 0 {
+		return 1
+	}
+	return 0
+}
+
+// Формирование отчёта по карточкам объектов
+func Build(sites []andromeda.GetSitesResponse, opts Options) Report {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	days := opts.UpcomingDays
+	if days <= 0 {
+		days = defaultUpcomingDays
+	}
+	groupBy := opts.GroupBy
+	if groupBy == "" {
+		groupBy = GroupByType
+	}
+	expected := opts.ExpectedDebtLevel
+	if expected == nil {
+		expected = defaultDebtLevel
+	}
+	today := day(now)
+	horizon := today.AddDate(0, 0, days)
+
+	report := Report{
+		GeneratedAt:         now,
+		GroupBy:             groupBy,
+		Lang:                opts.Lang,
+		Groups:              []Group{},
+		Debtors:             []Site{},
+		UpcomingPayments:    []Site{},
+		DebtLevelMismatches: []Site{},
+	}
+	groups := map[string]*Group{}
+
+	for _, s := range sites {
+		site := FromSite(s)
+
+		key := site.TypeName
+		if groupBy == GroupByContract {
+			key = site.ContractNumber
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &Group{Key: key}
+			groups[key] = g
+		}
+
+		for _, g := range []*Group{g, &report.Total} {
+			g.Sites++
+			g.ContractPrice += site.ContractPrice
+			g.MoneyBalance += site.MoneyBalance
+			if site.MoneyBalance < 0 {
+				g.Debtors++
+				g.Debt -= site.MoneyBalance
+			}
+		}
+
+		if site.MoneyBalance < 0 {
+			report.Debtors = append(report.Debtors, site)
+		}
+		if payment := day(site.PaymentDate); !site.PaymentDate.IsZero() && !payment.Before(today) && payment.Before(horizon) {
+			report.UpcomingPayments = append(report.UpcomingPayments, site)
+		}
+		if (expected(site) > 0) != (site.DebtInformLevel > 0) {
+			report.DebtLevelMismatches = append(report.DebtLevelMismatches, site)
+		}
+	}
+
+	for _, g := range groups {
+		report.Groups = append(report.Groups, *g)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool { return report.Groups[i].Key < report.Groups[j].Key })
+	sort.SliceStable(report.Debtors, func(i, j int) bool { return report.Debtors[i].MoneyBalance < report.Debtors[j].MoneyBalance })
+	sort.SliceStable(report.UpcomingPayments, func(i, j int) bool {
+		return report.UpcomingPayments[i].PaymentDate.Before(report.UpcomingPayments[j].PaymentDate)
+	})
+
+	return report
+}
+
+// Календарный день t без времени и часового пояса
+func day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Загрузка карточек объектов для отчёта. Объекты, которые не удалось получить, возвращаются в ошибках по номеру или идентификатору
+func Fetch(ctx context.Context, client API, config andromeda.Config, userName string, ids []string) ([]andromeda.GetSitesResponse, map[string]error) {
+	results, _ := andromeda.Bulk(ctx, ids, func(ctx context.Context, id string) (andromeda.GetSitesResponse, error) {
+		return client.GetSites(ctx, andromeda.GetSitesInput{Id: id, UserName: userName, Config: config})
+	}, andromeda.BulkOptions{})
+
+	sites := make([]andromeda.GetSitesResponse, 0, len(results))
+	failed := map[string]error{}
+	for _, r := range results {
+		if r.Err != nil {
+			failed[r.Input] = r.Err
+			continue
+		}
+		sites = append(sites, r.Output)
+	}
+
+	return sites, failed
+}
+
+// Вывод отчёта в формате JSON
+func (r Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return &andromeda.Error{Code: andromeda.CodeReportWrite, Err: err}
+	}
+
+	return nil
+}
+
+// Вывод отчёта в виде текстовых таблиц на языке Options.Lang
+func (r Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	l := labelsFor(r.Lang)
+
+	groupTitle := l.siteType
+	if r.GroupBy == GroupByContract {
+		groupTitle = l.contract
+	}
+	fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t\n", groupTitle, l.sites, l.debtors, l.contractPrice, l.balance, l.debt)
+	for _, g := range r.Groups {
+		writeGroup(tw, l, g.Key, g)
+	}
+	writeGroup(tw, l, l.total, r.Total)
+
+	writeSites(tw, l, l.debtorsTitle, r.Debtors)
+	writeSites(tw, l, l.upcomingTitle, r.UpcomingPayments)
+	writeSites(tw, l, l.mismatchTitle, r.DebtLevelMismatches)
+
+	if err := tw.Flush(); err != nil {
+		return &andromeda.Error{Code: andromeda.CodeReportWrite, Err: err, Lang: andromeda.Lang(r.Lang)}
+	}
+
+	return nil
+}
+
+func writeGroup(w io.Writer, l textLabels, title string, g Group) {
+	fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%s\t\n", title, g.Sites, g.Debtors, l.money(g.ContractPrice), l.money(g.MoneyBalance), l.money(g.Debt))
+}
+
+func writeSites(w io.Writer, l textLabels, title string, sites []Site) {
+	fmt.Fprintf(w, "\n%s: %d\n", title, len(sites))
+	if len(sites) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", l.number, l.name, l.contract, l.contractPrice, l.balance, l.paymentDate, l.debtLevel)
+	for _, s := range sites {
+		date := ""
+		if !s.PaymentDate.IsZero() {
+			date = s.PaymentDate.Format(l.dateFormat)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%d\t\n",
+			s.AccountNumber, s.Name, s.ContractNumber, l.money(s.ContractPrice), l.money(s.MoneyBalance), date, s.DebtInformLevel)
+	}
+}
@@ -0,0 +1,170 @@
+package billing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+// Момент формирования отчёта: вечер 18 октября
+var testNow = time.Date(2026, 10, 18, 21, 30, 0, 0, time.Local)
+
+func testSites() []andromeda.GetSitesResponse {
+	return []andromeda.GetSitesResponse{
+		{Id: "s1", AccountNumber: 1, TypeName: "Квартира", ContractNumber: "Д-1", ContractPrice: 50000, MoneyBalance: -120050, DebtInformLevel: 1, PaymentDate: "18.10.2026"},
+		{Id: "s2", AccountNumber: 2, TypeName: "Квартира", ContractNumber: "Д-2", ContractPrice: 50000, MoneyBalance: 10000, DebtInformLevel: 1, PaymentDate: "2026-10-24"},
+		{Id: "s3", AccountNumber: 3, TypeName: "Офис", ContractNumber: "Д-1", ContractPrice: 150000, MoneyBalance: -5000, PaymentDate: "2026-10-25T00:00:00"},
+		{Id: "s4", AccountNumber: 4, TypeName: "Офис", ContractNumber: "Д-3", ContractPrice: 150000, MoneyBalance: 0, PaymentDate: "17.10.2026"},
+		{Id: "s5", AccountNumber: 5, TypeName: "Офис", ContractNumber: "Д-3", PaymentDate: "не задана"},
+	}
+}
+
+func ids(sites []Site) string {
+	out := make([]string, len(sites))
+	for idx, s := range sites {
+		out[idx] = s.Id
+	}
+	return strings.Join(out, ",")
+}
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       Options
+		groups     string //Ключи групп через запятую
+		debtors    string
+		upcoming   string
+		mismatches string
+	}{
+		{
+			name:       "по типу объекта",
+			opts:       Options{Now: testNow},
+			groups:     "Квартира,Офис",
+			debtors:    "s1,s3",
+			upcoming:   "s1,s2",
+			mismatches: "s2,s3",
+		},
+		{
+			name:       "по договору с горизонтом 8 дней",
+			opts:       Options{Now: testNow, UpcomingDays: 8, GroupBy: GroupByContract},
+			groups:     "Д-1,Д-2,Д-3",
+			debtors:    "s1,s3",
+			upcoming:   "s1,s2,s3",
+			mismatches: "s2,s3",
+		},
+		{
+			name: "свой уровень информирования",
+			opts: Options{Now: testNow, ExpectedDebtLevel: func(site Site) int {
+				return 1
+			}},
+			groups:     "Квартира,Офис",
+			debtors:    "s1,s3",
+			upcoming:   "s1,s2",
+			mismatches: "s3,s4,s5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Build(testSites(), tt.opts)
+
+			keys := make([]string, len(r.Groups))
+			for idx, g := range r.Groups {
+				keys[idx] = g.Key
+			}
+			if got := strings.Join(keys, ","); got != tt.groups {
+				t.Errorf("группы %s, ожидались %s", got, tt.groups)
+			}
+			if got := ids(r.Debtors); got != tt.debtors {
+				t.Errorf("должники %s, ожидались %s", got, tt.debtors)
+			}
+			if got := ids(r.UpcomingPayments); got != tt.upcoming {
+				t.Errorf("ближайшие списания %s, ожидались %s", got, tt.upcoming)
+			}
+			if got := ids(r.DebtLevelMismatches); got != tt.mismatches {
+				t.Errorf("неверный уровень информирования %s, ожидались %s", got, tt.mismatches)
+			}
+		})
+	}
+}
+
+func TestBuildTotal(t *testing.T) {
+	r := Build(testSites(), Options{Now: testNow})
+
+	want := Group{Sites: 5, Debtors: 2, ContractPrice: 400000, MoneyBalance: -115050, Debt: 125050}
+	if r.Total != want {
+		t.Fatalf("итоги %+v, ожидались %+v", r.Total, want)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+		skip []string //Строки, которых не должно быть в отчёте
+	}{
+		{
+			name: "русский по умолчанию",
+			opts: Options{Now: testNow},
+			want: []string{"Тип объекта", "Итого", "Должники: 2", "Ближайшие списания: 2", "18.10.2026", "200,50 ₽"},
+		},
+		{
+			name: "английский",
+			opts: Options{Now: testNow, Lang: LangEN, GroupBy: GroupByContract},
+			want: []string{"Contract", "Total", "Debtors: 2", "Upcoming payments: 2", "2026-10-18", "-1200.50"},
+			skip: []string{"Итого", "Договор", "Должники", "₽"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Build(testSites(), tt.opts).WriteText(&buf); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("отчёт не содержит %q:\n%s", want, buf.String())
+				}
+			}
+			for _, skip := range tt.skip {
+				if strings.Contains(buf.String(), skip) {
+					t.Errorf("отчёт содержит %q:\n%s", skip, buf.String())
+				}
+			}
+		})
+	}
+}
+
+// Ответы GetSites по идентификатору объекта
+type fakeAPI map[string]andromeda.GetSitesResponse
+
+func (f fakeAPI) GetSites(ctx context.Context, in andromeda.GetSitesInput) (andromeda.GetSitesResponse, error) {
+	site, ok := f[in.Id]
+	if !ok {
+		return site, errors.New("not found")
+	}
+	return site, nil
+}
+
+func TestFetch(t *testing.T) {
+	api := fakeAPI{}
+	for _, s := range testSites() {
+		api[s.Id] = s
+	}
+
+	sites, failed := Fetch(context.Background(), api, andromeda.Config{}, "user", []string{"s1", "missing", "s2"})
+	if len(sites) != 2 || sites[0].Id != "s1" || sites[1].Id != "s2" {
+		t.Fatalf("объекты %+v, ожидались s1 и s2", sites)
+	}
+	if len(failed) != 1 || failed["missing"] == nil {
+		t.Fatalf("ошибки %v, ожидалась ошибка для missing", failed)
+	}
+}
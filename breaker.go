@@ -0,0 +1,249 @@
+package andromeda
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Запрос не отправлен: по серверу (или методу) разомкнут автоматический выключатель
+var ErrCircuitOpen = &Error{Code: CodeCircuitOpen}
+
+const (
+	CircuitClosed   CircuitState = iota //Запросы выполняются
+	CircuitOpen                         //Запросы не выполняются до истечения CoolDown
+	CircuitHalfOpen                     //Выполняются пробные запросы
+)
+
+const (
+	defaultBreakerRatio       = 0.5
+	defaultBreakerMinRequests = 10
+	defaultBreakerWindow      = 30 * time.Second
+	defaultBreakerCoolDown    = 15 * time.Second
+)
+
+type (
+	//Состояние автоматического выключателя
+	CircuitState int
+
+	//Параметры автоматического выключателя
+	BreakerOptions struct {
+		FailureRatio     float64             //Доля неудачных запросов в окне, при которой выключатель размыкается (по умолчанию 0.5)
+		MinRequests      int                 //Минимальное количество запросов в окне для оценки доли (по умолчанию 10)
+		Window           time.Duration       //Окно подсчёта запросов (по умолчанию 30 секунд)
+		CoolDown         time.Duration       //Время до пробных запросов после размыкания (по умолчанию 15 секунд)
+		HalfOpenRequests int                 //Количество одновременных пробных запросов (по умолчанию 1)
+		PerEndpoint      bool                //Отдельный выключатель для каждого метода сервера
+		OnStateChange    func(CircuitChange) //Вызывается при смене состояния (необязательное поле)
+	}
+
+	//Смена состояния выключателя
+	CircuitChange struct {
+		Host     string //Адрес сервера
+		Endpoint string //Метод SDK, если BreakerOptions.PerEndpoint
+		From     CircuitState
+		To       CircuitState
+		Time     time.Time
+	}
+
+	breaker struct {
+		opts BreakerOptions
+
+		mu       sync.Mutex
+		circuits map[string]*circuit
+	}
+
+	circuit struct {
+		host     string
+		endpoint string
+		state    CircuitState
+
+		windowStart time.Time
+		requests    int
+		failures    int
+		openedAt    time.Time
+		probes      int
+		generation  int //Номер состояния, увеличивается при каждой смене состояния
+	}
+
+	//Разрешение на запрос: выключатель и состояние, в котором запрос был разрешён
+	permit struct {
+		cb         *circuit
+		state      CircuitState
+		generation int
+	}
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+
+	return "closed"
+}
+
+// Автоматический выключатель для каждого сервера (и метода, если opts.PerEndpoint).
+// Неудачей считается ошибка соединения или ответ 5xx. Пока выключатель разомкнут, запросы сразу
+// завершаются ошибкой ErrCircuitOpen; при резервировании серверов запрос уходит на следующий сервер группы
+func WithCircuitBreaker(opts BreakerOptions) Option {
+	return func(c *Client) {
+		if opts.FailureRatio <= 0 {
+			opts.FailureRatio = defaultBreakerRatio
+		}
+		if opts.MinRequests <= 0 {
+			opts.MinRequests = defaultBreakerMinRequests
+		}
+		if opts.Window <= 0 {
+			opts.Window = defaultBreakerWindow
+		}
+		if opts.CoolDown <= 0 {
+			opts.CoolDown = defaultBreakerCoolDown
+		}
+		if opts.HalfOpenRequests <= 0 {
+			opts.HalfOpenRequests = 1
+		}
+		c.breaker = &breaker{opts: opts, circuits: map[string]*circuit{}}
+	}
+}
+
+// Состояние выключателей по серверу (и методу через «|»)
+func (c *Client) CircuitStates() map[string]CircuitState {
+	states := map[string]CircuitState{}
+	if c.breaker == nil {
+		return states
+	}
+
+	b := c.breaker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	for key, cb := range b.circuits {
+		state := cb.state
+		if state == CircuitOpen && now.Sub(cb.openedAt) >= b.opts.CoolDown {
+			state = CircuitHalfOpen
+		}
+		states[key] = state
+	}
+
+	return states
+}
+
+// Разрешение запроса к серверу addr. Возвращает разрешение, с которым нужно сообщить результат в done
+func (b *breaker) allow(addr, endpoint string, lang Lang) (permit, error) {
+	host := addr
+	if u, err := url.Parse(addr); err == nil {
+		host = u.Scheme + "://" + u.Host
+	}
+	key := host
+	if !b.opts.PerEndpoint {
+		endpoint = ""
+	} else {
+		key += "|" + endpoint
+	}
+
+	b.mu.Lock()
+	cb, ok := b.circuits[key]
+	if !ok {
+		cb = &circuit{host: host, endpoint: endpoint}
+		b.circuits[key] = cb
+	}
+
+	now := time.Now()
+	var change *CircuitChange
+	switch cb.state {
+	case CircuitOpen:
+		if now.Sub(cb.openedAt) < b.opts.CoolDown {
+			b.mu.Unlock()
+			return permit{}, &Error{Code: CodeCircuitOpen, Detail: key, Lang: lang}
+		}
+		change = b.set(cb, CircuitHalfOpen, now)
+		fallthrough
+	case CircuitHalfOpen:
+		if cb.probes >= b.opts.HalfOpenRequests {
+			b.mu.Unlock()
+			b.notify(change)
+			return permit{}, &Error{Code: CodeCircuitOpen, Detail: key, Lang: lang}
+		}
+		cb.probes++
+	default:
+		if now.Sub(cb.windowStart) >= b.opts.Window {
+			cb.windowStart, cb.requests, cb.failures = now, 0, 0
+		}
+	}
+	p := permit{cb: cb, state: cb.state, generation: cb.generation}
+	b.mu.Unlock()
+	b.notify(change)
+
+	return p, nil
+}
+
+// Учёт результата запроса. Отмена запроса вызывающей стороной не считается ни успехом, ни неудачей.
+// Результат запроса, разрешённого до последней смены состояния, не учитывается: например, запрос,
+// начатый при замкнутом выключателе, не может решить судьбу пробных запросов
+func (b *breaker) done(ctx context.Context, p permit, resp *http.Response, err error) {
+	failed := err != nil || (resp != nil && resp.StatusCode >= http.StatusInternalServerError)
+	counted := !(err != nil && ctx.Err() != nil)
+
+	b.mu.Lock()
+	cb := p.cb
+	if p.generation != cb.generation {
+		b.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	var change *CircuitChange
+	switch p.state {
+	case CircuitHalfOpen:
+		cb.probes--
+		if !counted {
+			break
+		}
+		if failed {
+			change = b.set(cb, CircuitOpen, now)
+		} else {
+			change = b.set(cb, CircuitClosed, now)
+		}
+	case CircuitClosed:
+		if !counted {
+			break
+		}
+		cb.requests++
+		if failed {
+			cb.failures++
+		}
+		if cb.requests >= b.opts.MinRequests && float64(cb.failures)/float64(cb.requests) >= b.opts.FailureRatio {
+			change = b.set(cb, CircuitOpen, now)
+		}
+	}
+	b.mu.Unlock()
+	b.notify(change)
+}
+
+// Смена состояния под блокировкой. Событие передаётся в notify после снятия блокировки
+func (b *breaker) set(cb *circuit, state CircuitState, now time.Time) *CircuitChange {
+	change := &CircuitChange{Host: cb.host, Endpoint: cb.endpoint, From: cb.state, To: state, Time: now}
+	cb.state = state
+	cb.generation++
+	cb.probes = 0
+	switch state {
+	case CircuitOpen:
+		cb.openedAt = now
+	case CircuitClosed:
+		cb.windowStart, cb.requests, cb.failures = now, 0, 0
+	}
+
+	return change
+}
+
+func (b *breaker) notify(change *CircuitChange) {
+	if change != nil && b.opts.OnStateChange != nil {
+		b.opts.OnStateChange(*change)
+	}
+}
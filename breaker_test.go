@@ -0,0 +1,150 @@
+package andromeda
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const testAddr = "https://andromeda.local/Sites"
+
+func newTestBreaker(opts BreakerOptions) *breaker {
+	c := NewClient(WithCircuitBreaker(opts))
+	return c.breaker
+}
+
+func (b *breaker) state(t *testing.T) CircuitState {
+	t.Helper()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, cb := range b.circuits {
+		return cb.state
+	}
+	t.Fatal("выключатель не создан")
+	return 0
+}
+
+func mustAllow(t *testing.T, b *breaker) permit {
+	t.Helper()
+
+	p, err := b.allow(testAddr, "GetSites", LangRU)
+	if err != nil {
+		t.Fatalf("запрос не разрешён: %v", err)
+	}
+	return p
+}
+
+func mustDeny(t *testing.T, b *breaker) {
+	t.Helper()
+
+	if _, err := b.allow(testAddr, "GetSites", LangRU); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("ошибка %v, ожидалась ErrCircuitOpen", err)
+	}
+}
+
+var (
+	respOK   = &http.Response{StatusCode: http.StatusOK}
+	respFail = &http.Response{StatusCode: http.StatusBadGateway}
+)
+
+// Размыкание выключателя двумя неудачными запросами и ожидание пробного режима
+func openBreaker(t *testing.T, b *breaker) {
+	t.Helper()
+
+	for range 2 {
+		b.done(context.Background(), mustAllow(t, b), respFail, nil)
+	}
+	if got := b.state(t); got != CircuitOpen {
+		t.Fatalf("состояние %s, ожидалось open", got)
+	}
+	mustDeny(t, b)
+	time.Sleep(b.opts.CoolDown)
+}
+
+func TestBreaker(t *testing.T) {
+	tests := []struct {
+		name   string
+		probes int //BreakerOptions.HalfOpenRequests
+		run    func(t *testing.T, b *breaker)
+		want   CircuitState
+	}{
+		{
+			name:   "успешный пробный запрос замыкает выключатель",
+			probes: 1,
+			run: func(t *testing.T, b *breaker) {
+				openBreaker(t, b)
+				p := mustAllow(t, b)
+				mustDeny(t, b)
+				b.done(context.Background(), p, respOK, nil)
+			},
+			want: CircuitClosed,
+		},
+		{
+			name:   "неудачный пробный запрос снова размыкает выключатель",
+			probes: 1,
+			run: func(t *testing.T, b *breaker) {
+				openBreaker(t, b)
+				b.done(context.Background(), mustAllow(t, b), respFail, nil)
+				mustDeny(t, b)
+			},
+			want: CircuitOpen,
+		},
+		{
+			name:   "отменённый пробный запрос освобождает место для следующего",
+			probes: 1,
+			run: func(t *testing.T, b *breaker) {
+				openBreaker(t, b)
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				b.done(ctx, mustAllow(t, b), nil, context.Canceled)
+				mustAllow(t, b)
+			},
+			want: CircuitHalfOpen,
+		},
+		{
+			name:   "запрос, начатый до размыкания, не решает судьбу пробного режима",
+			probes: 1,
+			run: func(t *testing.T, b *breaker) {
+				early := mustAllow(t, b)
+				openBreaker(t, b)
+				probe := mustAllow(t, b)
+				b.done(context.Background(), early, respOK, nil)
+				mustDeny(t, b)
+				b.done(context.Background(), probe, respFail, nil)
+			},
+			want: CircuitOpen,
+		},
+		{
+			name:   "пробный запрос, завершённый после размыкания другим, не занимает место",
+			probes: 2,
+			run: func(t *testing.T, b *breaker) {
+				openBreaker(t, b)
+				first, second := mustAllow(t, b), mustAllow(t, b)
+				b.done(context.Background(), first, respFail, nil)
+				b.done(context.Background(), second, respOK, nil)
+				if got := b.state(t); got != CircuitOpen {
+					t.Fatalf("состояние %s, ожидалось open", got)
+				}
+
+				time.Sleep(b.opts.CoolDown)
+				mustAllow(t, b)
+				mustAllow(t, b)
+				mustDeny(t, b)
+			},
+			want: CircuitHalfOpen,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBreaker(BreakerOptions{MinRequests: 2, FailureRatio: 0.5, CoolDown: 10 * time.Millisecond, HalfOpenRequests: tt.probes})
+			tt.run(t, b)
+			if got := b.state(t); got != tt.want {
+				t.Fatalf("состояние %s, ожидалось %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package andromeda
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Ошибка, которой помечаются элементы, не выполненные из-за остановки пакетной операции
+var ErrBulkSkipped = &Error{Code: CodeBulkSkipped}
+
+const defaultBulkConcurrency = 4
+
+type (
+	//Параметры пакетного выполнения запросов
+	BulkOptions struct {
+		Concurrency   int     //Количество одновременно выполняемых запросов (по умолчанию 4)
+		RatePerSecond float64 //Максимальное количество запросов в секунду, 0 - без ограничения
+		StopOnError   bool    //Остановить обработку после первой ошибки
+	}
+
+	//Результат выполнения запроса для одного элемента пакета
+	BulkResult[In, Out any] struct {
+		Index    int           //Порядковый номер элемента во входном срезе
+		Input    In            //Входные данные
+		Output   Out           //Ответ метода
+		Err      error         //Ошибка выполнения, ErrBulkSkipped если элемент не обрабатывался
+		Duration time.Duration //Время выполнения запроса
+	}
+
+	//Сводный отчёт о пакетной операции
+	BulkReport struct {
+		Total     int           //Всего элементов
+		Succeeded int           //Выполнено успешно
+		Failed    int           //Выполнено с ошибкой
+		Skipped   int           //Не выполнялось
+		Duration  time.Duration //Общее время выполнения
+	}
+)
+
+// Пакетное выполнение метода SDK для каждого элемента inputs с ограничением параллельности и частоты запросов.
+// Результаты возвращаются в порядке входного среза.
+func Bulk[In, Out any](ctx context.Context, inputs []In, fn func(context.Context, In) (Out, error), opts BulkOptions) ([]BulkResult[In, Out], BulkReport) {
+	start := time.Now()
+	results := make([]BulkResult[In, Out], len(inputs))
+	for idx, in := range inputs {
+		results[idx] = BulkResult[In, Out]{Index: idx, Input: in, Err: ErrBulkSkipped}
+	}
+
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = defaultBulkConcurrency
+	}
+	if workers > len(inputs) {
+		workers = len(inputs)
+	}
+
+	stop, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var tick <-chan time.Time
+	if opts.RatePerSecond > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.RatePerSecond))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				if tick != nil {
+					select {
+					case <-tick:
+					case <-stop.Done():
+						continue
+					}
+				}
+				if stop.Err() != nil {
+					continue
+				}
+
+				began := time.Now()
+				out, err := fn(ctx, inputs[idx])
+				results[idx].Output = out
+				results[idx].Err = err
+				results[idx].Duration = time.Since(began)
+
+				if err != nil && opts.StopOnError {
+					cancel()
+				}
+			}
+		}()
+	}
+
+feed:
+	for idx := range inputs {
+		select {
+		case jobs <- idx:
+		case <-stop.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	report := BulkReport{Total: len(inputs), Duration: time.Since(start)}
+	for _, r := range results {
+		switch {
+		case errors.Is(r.Err, ErrBulkSkipped):
+			report.Skipped++
+		case r.Err != nil:
+			report.Failed++
+		default:
+			report.Succeeded++
+		}
+	}
+
+	return results, report
+}
+
+// Адаптер для методов, возвращающих только ошибку (например PutChangeKTSUserMyAlarm), к сигнатуре Bulk
+func BulkNoResult[In any](fn func(context.Context, In) error) func(context.Context, In) (struct{}, error) {
+	return func(ctx context.Context, in In) (struct{}, error) {
+		return struct{}{}, fn(ctx, in)
+	}
+}
@@ -0,0 +1,126 @@
+package andromeda
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBulk(t *testing.T) {
+	errFail := errors.New("ошибка сервера")
+
+	tests := []struct {
+		name   string
+		inputs []int
+		fail   int //Элемент, запрос для которого завершается ошибкой, 0 - без ошибок
+		opts   BulkOptions
+		want   BulkReport
+	}{
+		{name: "все успешно", inputs: []int{1, 2, 3, 4, 5}, opts: BulkOptions{Concurrency: 2}, want: BulkReport{Total: 5, Succeeded: 5}},
+		{name: "ошибка не останавливает обработку", inputs: []int{1, 2, 3, 4}, fail: 2, want: BulkReport{Total: 4, Succeeded: 3, Failed: 1}},
+		{name: "остановка после первой ошибки", inputs: []int{1, 2, 3, 4}, fail: 1, opts: BulkOptions{Concurrency: 1, StopOnError: true}, want: BulkReport{Total: 4, Failed: 1, Skipped: 3}},
+		{name: "пустой пакет", want: BulkReport{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn := func(ctx context.Context, in int) (int, error) {
+				if in == tt.fail {
+					return 0, errFail
+				}
+				return in * 10, nil
+			}
+
+			results, report := Bulk(context.Background(), tt.inputs, fn, tt.opts)
+			report.Duration = 0
+			if report != tt.want {
+				t.Fatalf("отчёт %+v, ожидался %+v", report, tt.want)
+			}
+
+			for idx, r := range results {
+				switch {
+				case r.Index != idx || r.Input != tt.inputs[idx]:
+					t.Errorf("результат %d: элемент %d (%d), порядок нарушен", idx, r.Index, r.Input)
+				case r.Input == tt.fail && !errors.Is(r.Err, errFail):
+					t.Errorf("элемент %d: ошибка %v, ожидалась %v", r.Input, r.Err, errFail)
+				case r.Err == nil && r.Output != r.Input*10:
+					t.Errorf("элемент %d: ответ %d", r.Input, r.Output)
+				}
+			}
+		})
+	}
+}
+
+func TestBulkConcurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	fn := func(ctx context.Context, in int) (struct{}, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		return struct{}{}, nil
+	}
+
+	_, report := Bulk(context.Background(), make([]int, 12), fn, BulkOptions{Concurrency: 3})
+	if report.Succeeded != 12 {
+		t.Fatalf("выполнено %d, ожидалось 12", report.Succeeded)
+	}
+	if got := peak.Load(); got > 3 {
+		t.Fatalf("одновременно выполнялось %d запросов, ограничение 3", got)
+	}
+}
+
+func TestBulkRate(t *testing.T) {
+	start := time.Now()
+	_, report := Bulk(context.Background(), make([]int, 5), func(ctx context.Context, in int) (int, error) {
+		return in, nil
+	}, BulkOptions{Concurrency: 5, RatePerSecond: 100})
+
+	if report.Succeeded != 5 {
+		t.Fatalf("выполнено %d, ожидалось 5", report.Succeeded)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Fatalf("5 запросов при 100 в секунду выполнены за %s", elapsed)
+	}
+}
+
+func TestBulkCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, report := Bulk(ctx, []int{1, 2, 3}, func(ctx context.Context, in int) (int, error) {
+		return in, nil
+	}, BulkOptions{})
+
+	if report.Skipped != 3 {
+		t.Fatalf("пропущено %d, ожидалось 3", report.Skipped)
+	}
+	for _, r := range results {
+		if !errors.Is(r.Err, ErrBulkSkipped) || CodeOf(r.Err) != CodeBulkSkipped {
+			t.Fatalf("элемент %d: ошибка %v, ожидалась ErrBulkSkipped", r.Input, r.Err)
+		}
+	}
+}
+
+func TestBulkNoResult(t *testing.T) {
+	var calls atomic.Int32
+	fn := BulkNoResult(func(ctx context.Context, in string) error {
+		calls.Add(1)
+		if in == "" {
+			return errors.New("пустой идентификатор")
+		}
+		return nil
+	})
+
+	_, report := Bulk(context.Background(), []string{"a", "", "b"}, fn, BulkOptions{})
+	if report.Succeeded != 2 || report.Failed != 1 || calls.Load() != 3 {
+		t.Fatalf("отчёт %+v, вызовов %d", report, calls.Load())
+	}
+}
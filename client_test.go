@@ -0,0 +1,34 @@
+package andromeda
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// Тестовый сервер Андромеды, отвечающий handler и считающий запросы
+type countingServer struct {
+	*httptest.Server
+	hits atomic.Int32
+}
+
+func newCountingServer(t *testing.T, handler http.HandlerFunc) *countingServer {
+	t.Helper()
+
+	s := &countingServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.hits.Add(1)
+		handler(w, r)
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func jsonHandler(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}
+}
@@ -0,0 +1,108 @@
+// REST шлюз к Андромеде с собственными токенами доступа.
+//
+//	andromeda-gateway -tokens tokens.yaml [-listen :8080] [-audit-log audit.log] [-cert cert.pem -key key.pem]
+//
+// Файл -tokens содержит список токенов с правами и разрешёнными объектами (см. пакет gateway).
+// Адрес сервера и API ключ Андромеды берутся из флагов -host, -apikey
+// или переменных окружения ANDROMEDA_HOST, ANDROMEDA_API_KEY.
+// Записи журнала -audit-log подписываются ключом HMAC из переменной окружения ANDROMEDA_AUDIT_KEY;
+// хеш последней записи выводится при остановке шлюза и используется для проверки журнала (пакет auditlog).
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+	"github.com/EkzikP/sdk-andromeda-go/auditlog"
+	"github.com/EkzikP/sdk-andromeda-go/gateway"
+)
+
+func main() {
+	var (
+		listen     = flag.String("listen", ":8080", "адрес, на котором принимаются запросы")
+		tokensPath = flag.String("tokens", "", "файл токенов доступа (json или yaml)")
+		host       = flag.String("host", os.Getenv("ANDROMEDA_HOST"), "адрес сервера Андромеда")
+		apiKey     = flag.String("apikey", os.Getenv("ANDROMEDA_API_KEY"), "API ключ")
+		auditPath  = flag.String("audit-log", "", "файл журнала изменяющих запросов")
+		certFile   = flag.String("cert", "", "сертификат TLS")
+		keyFile    = flag.String("key", "", "закрытый ключ TLS")
+	)
+	flag.Parse()
+
+	if *tokensPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*listen, *tokensPath, *host, *apiKey, *auditPath, *certFile, *keyFile); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(listen, tokensPath, host, apiKey, auditPath, certFile, keyFile string) error {
+	tokens, err := gateway.LoadTokens(tokensPath)
+	if err != nil {
+		return err
+	}
+
+	var opts []andromeda.Option
+	if auditPath != "" {
+		var key []byte
+		if v := os.Getenv("ANDROMEDA_AUDIT_KEY"); v != "" {
+			key = []byte(v)
+		}
+		sink, err := auditlog.Open(auditPath, key)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			sink.Close()
+			fmt.Fprintf(os.Stderr, "журнал аудита: хеш последней записи %s\n", sink.Head())
+		}()
+		opts = append(opts, andromeda.WithAuditSink(sink, func(rec andromeda.AuditRecord, err error) {
+			fmt.Fprintf(os.Stderr, "журнал аудита: %s %s: %v\n", rec.Method, rec.UserName, err)
+		}))
+	}
+
+	gw, err := gateway.New(andromeda.NewClient(opts...), andromeda.Config{Host: host, ApiKey: apiKey}, tokens)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Addr:              listen,
+		Handler:           gw,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	errc := make(chan error, 1)
+	go func() {
+		if certFile != "" {
+			errc <- srv.ListenAndServeTLS(certFile, keyFile)
+		} else {
+			errc <- srv.ListenAndServe()
+		}
+	}()
+	fmt.Printf("Шлюз запущен на %s, токенов %d\n", listen, len(tokens))
+
+	select {
+	case err := <-errc:
+		return fmt.Errorf("ошибка запуска шлюза: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return srv.Shutdown(shutdown)
+}
@@ -0,0 +1,74 @@
+// Утилита приведения доступов MyAlarm к состоянию, описанному в файле.
+//
+//	andromeda-reconcile -state access.yaml [-dry-run] [-json] [-prune] [-yes]
+//
+// Адрес сервера и API ключ берутся из флагов -host, -apikey или переменных окружения ANDROMEDA_HOST, ANDROMEDA_API_KEY.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+	"github.com/EkzikP/sdk-andromeda-go/reconcile"
+)
+
+func main() {
+	var (
+		statePath = flag.String("state", "", "файл желаемого состояния (.yaml, .yml или .json)")
+		host      = flag.String("host", os.Getenv("ANDROMEDA_HOST"), "адрес сервера Андромеда")
+		apiKey    = flag.String("apikey", os.Getenv("ANDROMEDA_API_KEY"), "API ключ")
+		userName  = flag.String("user", "", "имя пользователя, от которого делаются запросы")
+		prune     = flag.Bool("prune", false, "отвязывать пользователей, отсутствующих в файле состояния")
+		dryRun    = flag.Bool("dry-run", false, "только показать план")
+		asJSON    = flag.Bool("json", false, "вывести план в формате JSON")
+		yes       = flag.Bool("yes", false, "применить план без подтверждения")
+	)
+	flag.Parse()
+
+	if *statePath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*statePath, *host, *apiKey, *userName, *prune, *dryRun, *asJSON, *yes); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(statePath, host, apiKey, userName string, prune, dryRun, asJSON, yes bool) error {
+	desired, err := reconcile.LoadFile(statePath)
+	if err != nil {
+		return err
+	}
+
+	r := &reconcile.Reconciler{
+		Client:   andromeda.NewClient(),
+		Config:   andromeda.Config{Host: host, ApiKey: apiKey},
+		UserName: userName,
+		Prune:    prune,
+	}
+
+	ctx := context.Background()
+	plan, err := r.Plan(ctx, desired)
+	if err != nil {
+		return err
+	}
+
+	opts := reconcile.ApplyOptions{DryRun: dryRun, Yes: yes, Output: os.Stdout}
+	if asJSON {
+		if err := plan.WriteJSON(os.Stdout); err != nil {
+			return err
+		}
+		opts.Output = os.Stderr
+	}
+	if !yes {
+		opts.Confirm = reconcile.ConfirmPrompt(os.Stdin, os.Stderr)
+	}
+
+	_, err = r.Apply(ctx, plan, opts)
+	return err
+}
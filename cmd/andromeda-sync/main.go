@@ -0,0 +1,122 @@
+// Утилита синхронизации объектов Андромеды в локальную базу SQLite.
+//
+//	andromeda-sync -db andromeda.db -sites sites.txt [-max-age 24h]
+//	andromeda-sync -db andromeda.db -range 1-9999
+//	andromeda-sync -db andromeda.db -sites sites.txt -prune
+//
+// Объекты задаются номерами или идентификаторами: по одному в строке файла -sites, диапазоном номеров -range
+// или аргументами командной строки. Адрес сервера и API ключ берутся из флагов -host, -apikey
+// или переменных окружения ANDROMEDA_HOST, ANDROMEDA_API_KEY. С -prune список считается полным
+// и объекты, которых в нём нет, удаляются из базы.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+	"github.com/EkzikP/sdk-andromeda-go/mirror"
+)
+
+func main() {
+	var (
+		dbPath      = flag.String("db", "andromeda.db", "файл базы SQLite")
+		sitesPath   = flag.String("sites", "", "файл со списком номеров или идентификаторов объектов")
+		accRange    = flag.String("range", "", "диапазон номеров объектов, например 1-9999")
+		host        = flag.String("host", os.Getenv("ANDROMEDA_HOST"), "адрес сервера Андромеда")
+		apiKey      = flag.String("apikey", os.Getenv("ANDROMEDA_API_KEY"), "API ключ")
+		userName    = flag.String("user", "", "имя пользователя, от которого делаются запросы")
+		concurrency = flag.Int("concurrency", 4, "количество объектов, загружаемых одновременно")
+		rate        = flag.Float64("rate", 0, "максимальное количество объектов в секунду, 0 - без ограничения")
+		maxAge      = flag.Duration("max-age", 0, "пропускать объекты, синхронизированные не раньше указанного времени назад")
+		prune       = flag.Bool("prune", false, "удалять из базы объекты, которых нет в списке")
+	)
+	flag.Parse()
+
+	sites, err := siteList(*sitesPath, *accRange, flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if len(sites) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	db, err := mirror.Open(*dbPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	s := &mirror.Syncer{
+		Client:        andromeda.NewClient(),
+		Config:        andromeda.Config{Host: *host, ApiKey: *apiKey},
+		DB:            db,
+		UserName:      *userName,
+		Concurrency:   *concurrency,
+		RatePerSecond: *rate,
+		MaxAge:        *maxAge,
+		Prune:         *prune,
+	}
+
+	run, err := s.Sync(ctx, sites)
+	fmt.Printf("Запуск %d: всего %d, синхронизировано %d, пропущено %d, ошибок %d, удалено %d, время %s\n",
+		run.Id, run.Total, run.Synced, run.Skipped, run.Failed, run.Removed, run.FinishedAt.Sub(run.StartedAt).Round(time.Millisecond))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if run.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// Список объектов из файла, диапазона и аргументов
+func siteList(path, accRange string, args []string) ([]string, error) {
+	sites := append([]string{}, args...)
+
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось открыть список объектов: %w", err)
+		}
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+				sites = append(sites, line)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("не удалось прочитать список объектов: %w", err)
+		}
+	}
+
+	if accRange != "" {
+		from, to, ok := strings.Cut(accRange, "-")
+		first, err1 := strconv.Atoi(from)
+		last, err2 := strconv.Atoi(to)
+		if !ok || err1 != nil || err2 != nil || first > last {
+			return nil, errors.New("неверно задан диапазон номеров объектов")
+		}
+		for n := first; n <= last; n++ {
+			sites = append(sites, strconv.Itoa(n))
+		}
+	}
+
+	return sites, nil
+}
@@ -0,0 +1,157 @@
+package andromeda
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCredentialsEnv  = "ANDROMEDA_API_KEY"
+	defaultCredentialsPoll = time.Second
+)
+
+type (
+	//Источник API ключа, который запрашивается перед каждым запросом. host - адрес сервера из Config.Host
+	CredentialsProvider interface {
+		APIKey(ctx context.Context, host string) (string, error)
+	}
+
+	//Источник, который может перечитать ключ. Refresh вызывается, если сервер ответил 401
+	CredentialsRefresher interface {
+		CredentialsProvider
+		Refresh(ctx context.Context) error
+	}
+
+	//Постоянный API ключ
+	StaticCredentials string
+
+	//API ключ из переменной окружения, читается при каждом запросе
+	EnvCredentials struct {
+		Name string //Имя переменной (по умолчанию ANDROMEDA_API_KEY)
+	}
+
+	//API ключ из файла. Файл перечитывается, если изменилось время его изменения,
+	//поэтому ключ можно заменить без перезапуска сервиса
+	FileCredentials struct {
+		Path         string
+		PollInterval time.Duration //Как часто проверять изменение файла (по умолчанию 1 секунда)
+
+		mu      sync.Mutex
+		key     string
+		modTime time.Time
+		checked time.Time
+	}
+)
+
+// Источник API ключа. Ключ источника заменяет Config.ApiKey, поле Config.ApiKey может быть пустым.
+// Если сервер ответил 401, ключ перечитывается и запрос повторяется один раз с новым ключом
+func WithCredentials(p CredentialsProvider) Option {
+	return func(c *Client) {
+		c.credentials = p
+	}
+}
+
+func (s StaticCredentials) APIKey(context.Context, string) (string, error) {
+	return string(s), nil
+}
+
+func (e EnvCredentials) APIKey(context.Context, string) (string, error) {
+	name := e.Name
+	if name == "" {
+		name = defaultCredentialsEnv
+	}
+	key := strings.TrimSpace(os.Getenv(name))
+	if key == "" {
+		return "", &Error{Code: CodeEnvNotSet, Detail: name}
+	}
+
+	return key, nil
+}
+
+func (e EnvCredentials) Refresh(context.Context) error {
+	return nil
+}
+
+func (f *FileCredentials) APIKey(context.Context, string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	poll := f.PollInterval
+	if poll <= 0 {
+		poll = defaultCredentialsPoll
+	}
+	if f.key == "" || time.Since(f.checked) >= poll {
+		if err := f.load(false); err != nil {
+			return "", err
+		}
+	}
+
+	return f.key, nil
+}
+
+// Принудительное чтение файла
+func (f *FileCredentials) Refresh(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.load(true)
+}
+
+func (f *FileCredentials) load(force bool) error {
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return &Error{Code: CodeKeyFileRead, Detail: f.Path, Err: err}
+	}
+	f.checked = time.Now()
+	if !force && f.key != "" && info.ModTime().Equal(f.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return &Error{Code: CodeKeyFileRead, Detail: f.Path, Err: err}
+	}
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return &Error{Code: CodeKeyFileEmpty, Detail: f.Path}
+	}
+	f.key, f.modTime = key, info.ModTime()
+
+	return nil
+}
+
+// API ключ запроса: из источника, если он задан, иначе из Config.ApiKey
+func (c *Client) apiKey(ctx context.Context, r request) (string, error) {
+	if c.credentials == nil {
+		return r.apiKey, nil
+	}
+
+	key, err := c.credentials.APIKey(ctx, r.host)
+	if err != nil {
+		return "", c.error(CodeCredentialsFailed, err, "")
+	}
+	if key == "" {
+		return "", c.error(CodeApiKeyRequired, nil, "")
+	}
+
+	return key, nil
+}
+
+// Обновление ключа после ответа 401. Возвращает новый ключ, если он отличается от использованного
+func (c *Client) refreshAPIKey(ctx context.Context, r request) (string, bool) {
+	refresher, ok := c.credentials.(CredentialsRefresher)
+	if !ok {
+		return "", false
+	}
+	if err := refresher.Refresh(ctx); err != nil {
+		return "", false
+	}
+	key, err := c.apiKey(ctx, r)
+	if err != nil || key == r.apiKey {
+		return "", false
+	}
+
+	return key, true
+}
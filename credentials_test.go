@@ -0,0 +1,167 @@
+package andromeda
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Сервер, принимающий только ключ valid и запоминающий ключи запросов
+type keyServer struct {
+	*countingServer
+	mu    sync.Mutex
+	valid string
+	keys  []string
+}
+
+func newKeyServer(t *testing.T, valid string) *keyServer {
+	s := &keyServer{valid: valid}
+	s.countingServer = newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		key := r.Header.Get("apiKey")
+		s.keys = append(s.keys, key)
+		if key != s.valid {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		jsonHandler(`{"Id":"s1"}`)(w, r)
+	})
+	return s
+}
+
+func writeKeyFile(t *testing.T, path, key string, modTime time.Time) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(key+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCredentialsProviders(t *testing.T) {
+	t.Setenv("TEST_ANDROMEDA_KEY", " env-key ")
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key")
+	writeKeyFile(t, keyFile, "file-key", time.Now())
+	emptyFile := filepath.Join(dir, "empty")
+	writeKeyFile(t, emptyFile, " ", time.Now())
+
+	tests := []struct {
+		name     string
+		provider CredentialsProvider
+		want     string
+		code     ErrorCode
+	}{
+		{name: "постоянный ключ", provider: StaticCredentials("static-key"), want: "static-key"},
+		{name: "переменная окружения", provider: EnvCredentials{Name: "TEST_ANDROMEDA_KEY"}, want: "env-key"},
+		{name: "переменная окружения не задана", provider: EnvCredentials{Name: "TEST_ANDROMEDA_MISSING"}, code: CodeEnvNotSet},
+		{name: "файл", provider: &FileCredentials{Path: keyFile}, want: "file-key"},
+		{name: "пустой файл", provider: &FileCredentials{Path: emptyFile}, code: CodeKeyFileEmpty},
+		{name: "файл отсутствует", provider: &FileCredentials{Path: filepath.Join(dir, "missing")}, code: CodeKeyFileRead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := tt.provider.APIKey(context.Background(), "")
+			if got := CodeOf(err); got != tt.code {
+				t.Fatalf("код ошибки %q, ожидался %q (%v)", got, tt.code, err)
+			}
+			if key != tt.want {
+				t.Fatalf("ключ %q, ожидался %q", key, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileCredentialsRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	start := time.Now().Add(-time.Hour)
+	writeKeyFile(t, path, "old", start)
+
+	creds := &FileCredentials{Path: path, PollInterval: time.Hour}
+	if key, _ := creds.APIKey(context.Background(), ""); key != "old" {
+		t.Fatalf("ключ %q, ожидался old", key)
+	}
+
+	// До истечения интервала проверки файл не перечитывается
+	writeKeyFile(t, path, "new", start.Add(time.Minute))
+	if key, _ := creds.APIKey(context.Background(), ""); key != "old" {
+		t.Fatalf("ключ %q до истечения интервала, ожидался old", key)
+	}
+
+	creds.PollInterval = time.Nanosecond
+	if key, _ := creds.APIKey(context.Background(), ""); key != "new" {
+		t.Fatalf("ключ %q после изменения файла, ожидался new", key)
+	}
+}
+
+func TestCredentialsRetryOn401(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider func(path string) CredentialsProvider
+		keys     []string //Ключи запросов к серверу
+		code     ErrorCode
+	}{
+		{
+			name:     "ключ перечитывается после ответа 401",
+			provider: func(path string) CredentialsProvider { return &FileCredentials{Path: path, PollInterval: time.Hour} },
+			keys:     []string{"old", "new"},
+		},
+		{
+			name:     "ключ без обновления не повторяется",
+			provider: func(string) CredentialsProvider { return StaticCredentials("old") },
+			keys:     []string{"old"},
+			code:     CodeHTTPStatus,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "key")
+			writeKeyFile(t, path, "old", time.Now().Add(-time.Hour))
+
+			srv := newKeyServer(t, "new")
+			c := NewClient(WithCredentials(tt.provider(path)))
+
+			// Ключ сменился на сервере и в файле после первого чтения
+			if _, err := c.apiKey(context.Background(), request{}); err != nil {
+				t.Fatal(err)
+			}
+			writeKeyFile(t, path, "new", time.Now())
+
+			_, err := c.GetSites(context.Background(), GetSitesInput{Id: "1", Config: Config{Host: srv.URL}})
+			if got := CodeOf(err); got != tt.code {
+				t.Fatalf("код ошибки %q, ожидался %q (%v)", got, tt.code, err)
+			}
+			if len(srv.keys) != len(tt.keys) {
+				t.Fatalf("ключи запросов %v, ожидалось %v", srv.keys, tt.keys)
+			}
+			for idx := range tt.keys {
+				if srv.keys[idx] != tt.keys[idx] {
+					t.Fatalf("ключи запросов %v, ожидалось %v", srv.keys, tt.keys)
+				}
+			}
+		})
+	}
+}
+
+func TestCredentialsFailed(t *testing.T) {
+	srv := newKeyServer(t, "key")
+	c := NewClient(WithCredentials(EnvCredentials{Name: "TEST_ANDROMEDA_MISSING"}))
+
+	_, err := c.GetSites(context.Background(), GetSitesInput{Id: "1", Config: Config{Host: srv.URL}})
+	if got := CodeOf(err); got != CodeCredentialsFailed {
+		t.Fatalf("код ошибки %q, ожидался %q (%v)", got, CodeCredentialsFailed, err)
+	}
+	if srv.hits.Load() != 0 {
+		t.Fatal("запрос без ключа отправлен на сервер")
+	}
+}
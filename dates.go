@@ -0,0 +1,35 @@
+package andromeda
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Форматы дат, в которых сервер возвращает PaymentDate, DisableDate, AutoEnableDate и другие поля
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"02.01.2006 15:04:05",
+	"02.01.2006",
+}
+
+// Разбор даты из строкового поля ответа. Даты без часового пояса считаются местным временем.
+// Для пустой строки возвращается нулевое время без ошибки
+func ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, &Error{Code: CodeDateFormat, Detail: strconv.Quote(s)}
+}
@@ -0,0 +1,43 @@
+package andromeda
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Time
+		err  bool
+	}{
+		{in: "2026-10-18T12:30:00+03:00", want: time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)},
+		{in: "2026-10-18T12:30:00.123", want: time.Date(2026, 10, 18, 12, 30, 0, 123000000, time.Local)},
+		{in: "2026-10-18T12:30:00", want: time.Date(2026, 10, 18, 12, 30, 0, 0, time.Local)},
+		{in: "2026-10-18 12:30:00", want: time.Date(2026, 10, 18, 12, 30, 0, 0, time.Local)},
+		{in: " 2026-10-18 ", want: time.Date(2026, 10, 18, 0, 0, 0, 0, time.Local)},
+		{in: "18.10.2026 12:30:00", want: time.Date(2026, 10, 18, 12, 30, 0, 0, time.Local)},
+		{in: "18.10.2026", want: time.Date(2026, 10, 18, 0, 0, 0, 0, time.Local)},
+		{in: ""},
+		{in: "18/10/2026", err: true},
+		{in: "2026-13-01", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseDate(tt.in)
+			if tt.err {
+				if CodeOf(err) != CodeDateFormat {
+					t.Fatalf("ошибка %v, ожидался код %q", err, CodeDateFormat)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Fatalf("%s, ожидалось %s", got, tt.want)
+			}
+		})
+	}
+}
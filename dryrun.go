@@ -0,0 +1,82 @@
+package andromeda
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// Изменяющий запрос не выполнен, так как включён пробный запуск
+var ErrDryRun = &Error{Code: CodeDryRun}
+
+type (
+	//Запрос, который был бы отправлен на сервер. API ключ не включается
+	PlannedRequest struct {
+		Method     string          `json:"method"`         //Метод SDK
+		HTTPMethod string          `json:"httpMethod"`     //HTTP метод
+		URL        string          `json:"url"`            //Полный адрес запроса
+		Host       string          `json:"host"`           //Адрес сервера
+		Path       string          `json:"path"`           //Путь без параметров
+		Query      url.Values      `json:"query"`          //Параметры строки запроса
+		Body       json.RawMessage `json:"body,omitempty"` //Тело запроса
+	}
+
+	//Ошибка изменяющего запроса в режиме пробного запуска. errors.Is(err, ErrDryRun) возвращает true
+	DryRunError struct {
+		Request PlannedRequest
+		Lang    Lang //Язык сообщения
+	}
+
+	dryRunKey struct{}
+)
+
+func (e *DryRunError) Error() string {
+	return CodeDryRun.Message(e.Lang) + ": " + e.Request.HTTPMethod + " " + e.Request.URL
+}
+
+func (e *DryRunError) Is(target error) bool {
+	return target == ErrDryRun
+}
+
+// Пробный запуск для всех запросов клиента: входные данные проверяются и запрос строится, но POST, PUT и DELETE
+// запросы не отправляются, а возвращают DryRunError. GET запросы выполняются как обычно.
+// report (необязательный параметр) вызывается для каждого неотправленного запроса
+func WithDryRun(report func(PlannedRequest)) Option {
+	return func(c *Client) {
+		c.dryRun = true
+		c.dryRunReport = report
+	}
+}
+
+// Пробный запуск для запросов с этим контекстом
+func DryRunContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// Проверка, нужно ли вместо отправки запроса вернуть PlannedRequest
+func (c *Client) isDryRun(ctx context.Context, method string) bool {
+	if method == http.MethodGet || method == http.MethodHead {
+		return false
+	}
+	if c.dryRun {
+		return true
+	}
+	on, _ := ctx.Value(dryRunKey{}).(bool)
+
+	return on
+}
+
+// Описание запроса для пробного запуска
+func (r request) planned(method string) PlannedRequest {
+	p := PlannedRequest{Method: r.name, HTTPMethod: method, URL: r.URL, Host: r.host}
+	if u, err := url.Parse(r.URL); err == nil {
+		p.Path = u.Path
+		p.Query = u.Query()
+	}
+	if len(r.body) > 0 {
+		p.Body = r.body
+	}
+
+	return p
+}
@@ -0,0 +1,122 @@
+package andromeda
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []Option
+		ctx        func(context.Context) context.Context
+		call       func(c *Client, ctx context.Context, cfg Config) error
+		httpMethod string //Пусто - запрос отправляется на сервер
+		query      string //Ожидаемое значение checkInterval
+	}{
+		{
+			name: "PUT не отправляется",
+			opts: []Option{WithDryRun(nil)},
+			call: func(c *Client, ctx context.Context, cfg Config) error {
+				_, err := c.PutChangeUserMyAlarm(ctx, PutChangeUserMyAlarmInput{CustId: "c1", Role: "admin", Config: cfg})
+				return err
+			},
+			httpMethod: http.MethodPut,
+		},
+		{
+			name: "POST не отправляется при пробном запуске через контекст",
+			ctx:  DryRunContext,
+			call: func(c *Client, ctx context.Context, cfg Config) error {
+				_, err := c.PostCheckPanic(ctx, PostCheckPanicInput{SiteId: "s1", CheckInterval: 60, Config: cfg})
+				return err
+			},
+			httpMethod: http.MethodPost,
+			query:      "60",
+		},
+		{
+			name: "GET выполняется",
+			opts: []Option{WithDryRun(nil)},
+			call: func(c *Client, ctx context.Context, cfg Config) error {
+				_, err := c.GetSites(ctx, GetSitesInput{Id: "1", Config: cfg})
+				return err
+			},
+		},
+		{
+			name: "без пробного запуска запрос отправляется",
+			call: func(c *Client, ctx context.Context, cfg Config) error {
+				return c.PutChangeKTSUserMyAlarm(ctx, PutChangeKTSUserMyAlarmInput{CustId: "c1", IsPanic: true, Config: cfg})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newCountingServer(t, jsonHandler(`{}`))
+			ctx := context.Background()
+			if tt.ctx != nil {
+				ctx = tt.ctx(ctx)
+			}
+
+			err := tt.call(NewClient(tt.opts...), ctx, Config{Host: srv.URL, ApiKey: "secret-key"})
+
+			if tt.httpMethod == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if srv.hits.Load() != 1 {
+					t.Fatalf("запросов к серверу %d, ожидался 1", srv.hits.Load())
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrDryRun) || CodeOf(err) != CodeDryRun {
+				t.Fatalf("ошибка %v, ожидалась ErrDryRun", err)
+			}
+			if srv.hits.Load() != 0 {
+				t.Fatal("изменяющий запрос отправлен на сервер")
+			}
+
+			var dryErr *DryRunError
+			if !errors.As(err, &dryErr) {
+				t.Fatalf("ошибка %T, ожидалась DryRunError", err)
+			}
+			p := dryErr.Request
+			if p.HTTPMethod != tt.httpMethod || p.Host != srv.URL || !strings.HasPrefix(p.URL, srv.URL+p.Path) || p.Method == "" {
+				t.Fatalf("запрос %+v", p)
+			}
+			if got := p.Query.Get("checkInterval"); got != tt.query {
+				t.Fatalf("checkInterval %q, ожидалось %q", got, tt.query)
+			}
+			if strings.Contains(p.URL+string(p.Body)+err.Error(), "secret-key") {
+				t.Fatal("API ключ попал в описание запроса")
+			}
+		})
+	}
+}
+
+func TestDryRunReport(t *testing.T) {
+	srv := newCountingServer(t, jsonHandler(`{}`))
+	var reported []PlannedRequest
+	c := NewClient(WithDryRun(func(p PlannedRequest) { reported = append(reported, p) }))
+
+	_, err := c.PutChangeUserMyAlarm(context.Background(), PutChangeUserMyAlarmInput{CustId: "c1", Role: "user", Config: Config{Host: srv.URL, ApiKey: "key"}})
+	if !errors.Is(err, ErrDryRun) {
+		t.Fatalf("ошибка %v, ожидалась ErrDryRun", err)
+	}
+	if len(reported) != 1 || reported[0].Query.Get("custId") != "c1" {
+		t.Fatalf("переданные запросы %+v", reported)
+	}
+
+	// Пробный запуск не отменяет проверку входных данных
+	_, err = c.PutChangeUserMyAlarm(context.Background(), PutChangeUserMyAlarmInput{Role: "user", Config: Config{Host: srv.URL, ApiKey: "key"}})
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Field("CustId") == nil {
+		t.Fatalf("ошибка %v, ожидалась ошибка проверки поля CustId", err)
+	}
+	if len(reported) != 1 {
+		t.Fatalf("переданных запросов %d, ожидался 1", len(reported))
+	}
+}
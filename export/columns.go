@@ -0,0 +1,235 @@
+// Пакет export выгружает карточки объектов, ответственных лиц, разделов и шлейфов в CSV и XLSX.
+package export
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+const (
+	LangRU = "ru"
+	LangEN = "en"
+
+	maskedValue = "***"
+)
+
+type (
+	//Типы записей, которые можно выгрузить
+	Record interface {
+		andromeda.GetSitesResponse | andromeda.GetCustomerResponse | andromeda.GetPartsResponse | andromeda.GetZonesResponse
+	}
+
+	//Параметры выгрузки
+	Options struct {
+		Columns       []string //Имена выгружаемых полей в нужном порядке (как в JSON ответа), пусто - все поля
+		Lang          string   //Язык заголовков: LangRU (по умолчанию) или LangEN
+		BOM           bool     //Добавить UTF-8 BOM в начало CSV (для открытия в Excel)
+		Comma         rune     //Разделитель CSV, по умолчанию ';'
+		ShowSensitive bool     //Не маскировать пароль объекта и PIN-код
+		SheetName     string   //Название листа XLSX, по умолчанию по типу записи
+	}
+
+	column struct {
+		key       string
+		title     string
+		index     int
+		sensitive bool
+	}
+
+	title struct {
+		ru string
+		en string
+	}
+)
+
+// Поля, значения которых по умолчанию маскируются
+var sensitiveFields = map[string]bool{
+	"ObjectPassword": true,
+	"PINCode":        true,
+}
+
+// Заголовки столбцов
+var titles = map[string]title{
+	"RowNumber":                  {"Порядковый номер", "Row number"},
+	"Id":                         {"Идентификатор", "ID"},
+	"AccountNumber":              {"Номер объекта", "Account number"},
+	"CloudObjectID":              {"Идентификатор в облаке", "Cloud object ID"},
+	"Name":                       {"Название объекта", "Site name"},
+	"ObjectPassword":             {"Пароль объекта", "Object password"},
+	"Address":                    {"Адрес", "Address"},
+	"Phone1":                     {"Телефон 1", "Phone 1"},
+	"Phone2":                     {"Телефон 2", "Phone 2"},
+	"TypeName":                   {"Тип объекта", "Site type"},
+	"IsFire":                     {"Пожарная сигнализация", "Fire alarm"},
+	"IsArm":                      {"Охранная сигнализация", "Intrusion alarm"},
+	"IsPanic":                    {"Тревожная кнопка", "Panic button"},
+	"DeviceTypeName":             {"Тип оборудования", "Device type"},
+	"EventTemplateName":          {"Шаблон событий", "Event template"},
+	"ContractNumber":             {"Номер договора", "Contract number"},
+	"ContractPrice":              {"Ежемесячный платёж", "Contract price"},
+	"MoneyBalance":               {"Баланс", "Money balance"},
+	"PaymentDate":                {"Дата списания", "Payment date"},
+	"DebtInformLevel":            {"Уровень информирования о долге", "Debt inform level"},
+	"Disabled":                   {"Отключен", "Disabled"},
+	"DisableReason":              {"Причина отключения", "Disable reason"},
+	"DisableDate":                {"Дата отключения", "Disable date"},
+	"AutoEnable":                 {"Автовключение", "Auto enable"},
+	"AutoEnableDate":             {"Дата автовключения", "Auto enable date"},
+	"CustomersComment":           {"Комментарий к ответственным", "Customers comment"},
+	"CommentForOperator":         {"Комментарий для оператора", "Comment for operator"},
+	"CommentForGuard":            {"Комментарий для ГБР", "Comment for guard"},
+	"MapFileName":                {"Файл карты", "Map file"},
+	"WebLink":                    {"Web-ссылка", "Web link"},
+	"ControlTime":                {"Контрольное время, мин", "Control time, min"},
+	"CTIgnoreSystemEvent":        {"Игнорировать системные события", "Ignore system events"},
+	"IsContractPriceForceUpdate": {"Принудительная запись платежа", "Force update contract price"},
+	"IsMoneyBalanceForceUpdate":  {"Принудительная запись баланса", "Force update money balance"},
+	"IsPaymentDateForceUpdate":   {"Принудительная запись даты списания", "Force update payment date"},
+	"IsStateArm":                 {"Взят", "Armed"},
+	"IsStateAlarm":               {"В тревоге", "In alarm"},
+	"IsStatePartArm":             {"Взят частично", "Partially armed"},
+	"StateArmDisArmDateTime":     {"Время взятия/снятия", "Arm/disarm time"},
+	"OrderNumber":                {"Порядковый номер", "Order number"},
+	"UserNumber":                 {"Номер пользователя", "User number"},
+	"ObjCustName":                {"ФИО", "Full name"},
+	"ObjCustTitle":               {"Должность", "Title"},
+	"ObjCustPhone1":              {"Мобильный телефон", "Mobile phone"},
+	"ObjCustPhone2":              {"Телефон 2", "Phone 2"},
+	"ObjCustPhone3":              {"Телефон 3", "Phone 3"},
+	"ObjCustPhone4":              {"Телефон 4", "Phone 4"},
+	"ObjCustPhone5":              {"Телефон 5", "Phone 5"},
+	"ObjCustAddress":             {"Адрес", "Address"},
+	"IsVisibleInCabinet":         {"Отображать в личном кабинете", "Visible in cabinet"},
+	"ReclosingRequest":           {"SMS о перезакрытии", "Reclosing request SMS"},
+	"ReclosingFailure":           {"SMS об отказе от перезакрытия", "Reclosing failure SMS"},
+	"PINCode":                    {"PIN-код", "PIN code"},
+	"PartNumber":                 {"Номер раздела", "Part number"},
+	"ObjectNumber":               {"Объектовый номер", "Object number"},
+	"PartDesc":                   {"Описание раздела", "Part description"},
+	"PartEquip":                  {"Оборудование раздела", "Part equipment"},
+	"ZoneNumber":                 {"Номер шлейфа", "Zone number"},
+	"ZoneDesc":                   {"Описание шлейфа", "Zone description"},
+	"ZoneEquip":                  {"Оборудование шлейфа", "Zone equipment"},
+}
+
+// Названия листов XLSX по умолчанию
+var sheetNames = map[reflect.Type]title{
+	reflect.TypeOf(andromeda.GetSitesResponse{}):    {"Объекты", "Sites"},
+	reflect.TypeOf(andromeda.GetCustomerResponse{}): {"Ответственные", "Customers"},
+	reflect.TypeOf(andromeda.GetPartsResponse{}):    {"Разделы", "Parts"},
+	reflect.TypeOf(andromeda.GetZonesResponse{}):    {"Шлейфы", "Zones"},
+}
+
+func (t title) get(lang string) string {
+	if lang == LangEN {
+		return t.en
+	}
+	return t.ru
+}
+
+// Список выгружаемых столбцов для типа записи T
+func columnsFor[T Record](opts Options) ([]column, error) {
+	typ := reflect.TypeOf(*new(T))
+
+	all := map[string]column{}
+	var order []string
+	for idx := 0; idx < typ.NumField(); idx++ {
+		f := typ.Field(idx)
+		if !f.IsExported() {
+			continue
+		}
+		key := strings.Split(f.Tag.Get("json"), ",")[0]
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			key = f.Name
+		}
+		switch f.Type.Kind() {
+		case reflect.Map, reflect.Slice, reflect.Struct, reflect.Pointer, reflect.Interface:
+			if _, ok := f.Type.MethodByName("String"); !ok {
+				continue
+			}
+		}
+
+		t, ok := titles[key]
+		if !ok {
+			t = title{key, key}
+		}
+		all[key] = column{key: key, title: t.get(opts.Lang), index: idx, sensitive: sensitiveFields[key]}
+		order = append(order, key)
+	}
+
+	if len(opts.Columns) > 0 {
+		order = opts.Columns
+	}
+
+	cols := make([]column, 0, len(order))
+	for _, key := range order {
+		col, ok := all[key]
+		if !ok {
+			return nil, &andromeda.Error{Code: andromeda.CodeExportField, Detail: key, Lang: andromeda.Lang(opts.Lang)}
+		}
+		cols = append(cols, col)
+	}
+
+	return cols, nil
+}
+
+// Заголовки столбцов
+func headers(cols []column) []string {
+	row := make([]string, len(cols))
+	for idx, col := range cols {
+		row[idx] = col.title
+	}
+	return row
+}
+
+// Значение поля записи с учётом маскирования
+func cellValue(rec reflect.Value, col column, opts Options) any {
+	v := rec.Field(col.index)
+	if col.sensitive && !opts.ShowSensitive {
+		if v.IsZero() {
+			return ""
+		}
+		return maskedValue
+	}
+
+	return v.Interface()
+}
+
+// Текстовое представление значения поля для CSV
+func formatValue(v any, lang string) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case bool:
+		if lang == LangEN {
+			return strconv.FormatBool(val)
+		}
+		if val {
+			return "да"
+		}
+		return "нет"
+	case int:
+		return strconv.Itoa(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case andromeda.Money:
+		if lang == LangEN {
+			return val.Decimal()
+		}
+		return strings.Replace(val.Decimal(), ".", ",", 1)
+	}
+
+	return fmt.Sprint(v)
+}
+
+// Ошибка записи выгрузки в формате format на языке выгрузки
+func writeError(format string, err error, lang string) error {
+	return &andromeda.Error{Code: andromeda.CodeReportWrite, Detail: format, Err: err, Lang: andromeda.Lang(lang)}
+}
@@ -0,0 +1,83 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"reflect"
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Потоковая запись записей в CSV
+type CSVWriter[T Record] struct {
+	w    *csv.Writer
+	cols []column
+	opts Options
+}
+
+// Создание CSV выгрузки. Заголовок (и BOM, если задан) записывается сразу
+func NewCSVWriter[T Record](w io.Writer, opts Options) (*CSVWriter[T], error) {
+	cols, err := columnsFor[T](opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.BOM {
+		if _, err := w.Write(utf8BOM); err != nil {
+			return nil, writeError("CSV", err, opts.Lang)
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = ';'
+	if opts.Comma != 0 {
+		cw.Comma = opts.Comma
+	}
+
+	if err := cw.Write(headers(cols)); err != nil {
+		return nil, writeError("CSV", err, opts.Lang)
+	}
+
+	return &CSVWriter[T]{w: cw, cols: cols, opts: opts}, nil
+}
+
+// Запись одной строки
+func (c *CSVWriter[T]) Write(rec T) error {
+	v := reflect.ValueOf(rec)
+	row := make([]string, len(c.cols))
+	for idx, col := range c.cols {
+		row[idx] = formatValue(cellValue(v, col, c.opts), c.opts.Lang)
+	}
+
+	if err := c.w.Write(row); err != nil {
+		return writeError("CSV", err, c.opts.Lang)
+	}
+
+	return nil
+}
+
+// Сброс буфера. Должен быть вызван после записи всех строк
+func (c *CSVWriter[T]) Flush() error {
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		return writeError("CSV", err, c.opts.Lang)
+	}
+
+	return nil
+}
+
+// Выгрузка среза записей в CSV
+func WriteCSV[T Record](w io.Writer, records []T, opts Options) error {
+	cw, err := NewCSVWriter[T](w, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, rec := range records {
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+
+	return cw.Flush()
+}
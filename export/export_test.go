@@ -0,0 +1,169 @@
+package export
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+	"github.com/xuri/excelize/v2"
+)
+
+var testCustomers = []andromeda.GetCustomerResponse{
+	{Id: "c1", UserNumber: 1, ObjCustName: "Иванов И.И.", IsVisibleInCabinet: true, PINCode: "1234"},
+	{Id: "c2", UserNumber: 2, ObjCustName: "Петров; П.П."},
+}
+
+var testSites = []andromeda.GetSitesResponse{
+	{Id: "s1", AccountNumber: 101, Name: "Склад", ObjectPassword: "secret", ContractPrice: andromeda.MoneyFromRubles(1500, 50)},
+}
+
+func TestWriteCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "выбранные столбцы на русском",
+			opts: Options{Columns: []string{"UserNumber", "ObjCustName", "IsVisibleInCabinet", "PINCode"}},
+			want: "Номер пользователя;ФИО;Отображать в личном кабинете;PIN-код\n1;Иванов И.И.;да;***\n2;\"Петров; П.П.\";нет;\n",
+		},
+		{
+			name: "заголовки на английском, разделитель запятая",
+			opts: Options{Columns: []string{"Id", "IsVisibleInCabinet"}, Lang: LangEN, Comma: ','},
+			want: "ID,Visible in cabinet\nc1,true\nc2,false\n",
+		},
+		{
+			name: "PIN-код без маскирования",
+			opts: Options{Columns: []string{"Id", "PINCode"}, ShowSensitive: true},
+			want: "Идентификатор;PIN-код\nc1;1234\nc2;\n",
+		},
+		{
+			name: "BOM",
+			opts: Options{Columns: []string{"Id"}, BOM: true},
+			want: "\xEF\xBB\xBFИдентификатор\nc1\nc2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteCSV(&buf, testCustomers, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Fatalf("выгрузка:\n%q\nожидалось:\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteCSVMoney(t *testing.T) {
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{lang: LangRU, want: "101;1500,50"},
+		{lang: LangEN, want: "101;1500.50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteCSV(&buf, testSites, Options{Columns: []string{"AccountNumber", "ContractPrice"}, Lang: tt.lang}); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Split(buf.String(), "\n")[1]; got != tt.want {
+				t.Fatalf("строка %q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnknownColumn(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func() error
+	}{
+		{name: "CSV", write: func() error {
+			return WriteCSV(&bytes.Buffer{}, testCustomers, Options{Columns: []string{"Id", "Extra"}})
+		}},
+		{name: "XLSX", write: func() error {
+			return WriteXLSX(&bytes.Buffer{}, testSites, Options{Columns: []string{"Unknown"}})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.write()
+			if got := andromeda.CodeOf(err); got != andromeda.CodeExportField {
+				t.Fatalf("код ошибки %q, ожидался %q (%v)", got, andromeda.CodeExportField, err)
+			}
+		})
+	}
+}
+
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) {
+	return 0, errors.New("диск заполнен")
+}
+
+func TestWriteError(t *testing.T) {
+	err := WriteCSV(failWriter{}, testCustomers, Options{BOM: true})
+	if got := andromeda.CodeOf(err); got != andromeda.CodeReportWrite {
+		t.Fatalf("код ошибки %q, ожидался %q (%v)", got, andromeda.CodeReportWrite, err)
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  Options
+		sheet string
+		rows  [][]string
+	}{
+		{
+			name:  "лист по типу записи",
+			opts:  Options{Columns: []string{"AccountNumber", "Name", "ContractPrice"}},
+			sheet: "Объекты",
+			rows:  [][]string{{"Номер объекта", "Название объекта", "Ежемесячный платёж"}, {"101", "Склад", "1500.5"}},
+		},
+		{
+			name:  "заданное название листа",
+			opts:  Options{Columns: []string{"Id", "ObjectPassword"}, Lang: LangEN, SheetName: "Export"},
+			sheet: "Export",
+			rows:  [][]string{{"ID", "Object password"}, {"s1", "***"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteXLSX(&buf, testSites, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+
+			file, err := excelize.OpenReader(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			rows, err := file.GetRows(tt.sheet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != len(tt.rows) {
+				t.Fatalf("строк %d, ожидалось %d: %v", len(rows), len(tt.rows), rows)
+			}
+			for idx := range tt.rows {
+				if strings.Join(rows[idx], "|") != strings.Join(tt.rows[idx], "|") {
+					t.Errorf("строка %d: %q, ожидалось %q", idx+1, rows[idx], tt.rows[idx])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package export
+
+import (
+	"io"
+	"reflect"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+	"github.com/xuri/excelize/v2"
+)
+
+// Потоковая запись записей в XLSX
+type XLSXWriter[T Record] struct {
+	file *excelize.File
+	sw   *excelize.StreamWriter
+	out  io.Writer
+	cols []column
+	opts Options
+	row  int
+}
+
+// Создание XLSX выгрузки. Файл записывается в w при вызове Close
+func NewXLSXWriter[T Record](w io.Writer, opts Options) (*XLSXWriter[T], error) {
+	cols, err := columnsFor[T](opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := opts.SheetName
+	if sheet == "" {
+		sheet = sheetNames[reflect.TypeOf(*new(T))].get(opts.Lang)
+	}
+
+	file := excelize.NewFile()
+	if err := file.SetSheetName(file.GetSheetName(0), sheet); err != nil {
+		file.Close()
+		return nil, writeError("XLSX", err, opts.Lang)
+	}
+
+	sw, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		file.Close()
+		return nil, writeError("XLSX", err, opts.Lang)
+	}
+
+	x := &XLSXWriter[T]{file: file, sw: sw, out: w, cols: cols, opts: opts, row: 1}
+
+	header := make([]any, len(cols))
+	for idx, h := range headers(cols) {
+		header[idx] = h
+	}
+	if err := x.writeRow(header); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// Запись одной строки
+func (x *XLSXWriter[T]) Write(rec T) error {
+	v := reflect.ValueOf(rec)
+	row := make([]any, len(x.cols))
+	for idx, col := range x.cols {
+		val := cellValue(v, col, x.opts)
+		switch v := val.(type) {
+		case string, bool, int, float64:
+			row[idx] = val
+		case andromeda.Money:
+			row[idx] = v.Float64()
+		default:
+			row[idx] = formatValue(val, x.opts.Lang)
+		}
+	}
+
+	return x.writeRow(row)
+}
+
+func (x *XLSXWriter[T]) writeRow(row []any) error {
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return writeError("XLSX", err, x.opts.Lang)
+	}
+	if err := x.sw.SetRow(cell, row); err != nil {
+		return writeError("XLSX", err, x.opts.Lang)
+	}
+	x.row++
+
+	return nil
+}
+
+// Завершение выгрузки и запись файла
+func (x *XLSXWriter[T]) Close() error {
+	defer x.file.Close()
+
+	if err := x.sw.Flush(); err != nil {
+		return writeError("XLSX", err, x.opts.Lang)
+	}
+	if _, err := x.file.WriteTo(x.out); err != nil {
+		return writeError("XLSX", err, x.opts.Lang)
+	}
+
+	return nil
+}
+
+// Выгрузка среза записей в XLSX
+func WriteXLSX[T Record](w io.Writer, records []T, opts Options) error {
+	xw, err := NewXLSXWriter[T](w, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, rec := range records {
+		if err := xw.Write(rec); err != nil {
+			xw.file.Close()
+			return err
+		}
+	}
+
+	return xw.Close()
+}
@@ -0,0 +1,166 @@
+package andromeda
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Структуры с теми же полями, что и у ответов с Extra, но без методов: ключ - тип ответа
+var plainTypes sync.Map
+
+// Разбор объекта JSON в r с сохранением неописанных в структуре полей в extra.
+// Используется в UnmarshalJSON структур ответов: собственный UnmarshalJSON при разборе не вызывается
+func unmarshalWithExtra[T any](data []byte, r *T, extra *map[string]json.RawMessage) error {
+	typ := reflect.TypeFor[T]()
+	plain := reflect.New(plainType(typ))
+	plain.Elem().Set(reflect.ValueOf(*r).Convert(plain.Elem().Type()))
+
+	err := json.Unmarshal(data, plain.Interface())
+	*r = plain.Elem().Convert(typ).Interface().(T)
+	if err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil || raw == nil {
+		*extra = nil
+		return nil
+	}
+
+	fields := jsonFields(typ)
+	for key := range raw {
+		if _, ok := matchField(fields, key); ok {
+			delete(raw, key)
+		}
+	}
+	if len(raw) == 0 {
+		raw = nil
+	}
+	*extra = raw
+
+	return nil
+}
+
+// Запись r в JSON с добавлением полей extra. Поля extra, совпадающие с полями структуры
+// (в том числе без учёта регистра), не записываются. Используется в MarshalJSON структур ответов
+func marshalWithExtra[T any](r T, extra map[string]json.RawMessage) ([]byte, error) {
+	typ := reflect.TypeFor[T]()
+	data, err := json.Marshal(reflect.ValueOf(r).Convert(plainType(typ)).Interface())
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+
+	fields := jsonFields(typ)
+	keys := make([]string, 0, len(extra))
+	for key := range extra {
+		if _, ok := matchField(fields, key); !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.Write(data[:len(data)-1])
+	for _, key := range keys {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(extra[key])
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// Структура с полями typ без методов, чтобы encoding/json не вызывал MarshalJSON и UnmarshalJSON typ повторно
+func plainType(typ reflect.Type) reflect.Type {
+	if plain, ok := plainTypes.Load(typ); ok {
+		return plain.(reflect.Type)
+	}
+
+	fields := make([]reflect.StructField, typ.NumField())
+	for idx := range fields {
+		fields[idx] = typ.Field(idx)
+	}
+	plain, _ := plainTypes.LoadOrStore(typ, reflect.StructOf(fields))
+
+	return plain.(reflect.Type)
+}
+
+func (r *PostCheckPanicResponse) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, r, &r.Extra)
+}
+
+func (r PostCheckPanicResponse) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(r, r.Extra)
+}
+
+func (r *PutChangeUserMyAlarmResponse) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, r, &r.Extra)
+}
+
+func (r PutChangeUserMyAlarmResponse) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(r, r.Extra)
+}
+
+func (r *GetCheckPanicResponse) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, r, &r.Extra)
+}
+
+func (r GetCheckPanicResponse) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(r, r.Extra)
+}
+
+func (r *UserMyAlarmResponse) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, r, &r.Extra)
+}
+
+func (r UserMyAlarmResponse) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(r, r.Extra)
+}
+
+func (r *GetUserObjectMyAlarmResponse) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, r, &r.Extra)
+}
+
+func (r GetUserObjectMyAlarmResponse) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(r, r.Extra)
+}
+
+func (r *GetPartsResponse) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, r, &r.Extra)
+}
+
+func (r GetPartsResponse) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(r, r.Extra)
+}
+
+func (r *GetZonesResponse) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, r, &r.Extra)
+}
+
+func (r GetZonesResponse) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(r, r.Extra)
+}
+
+func (r *GetSitesResponse) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, r, &r.Extra)
+}
+
+func (r GetSitesResponse) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(r, r.Extra)
+}
+
+func (r *GetCustomerResponse) UnmarshalJSON(data []byte) error {
+	return unmarshalWithExtra(data, r, &r.Extra)
+}
+
+func (r GetCustomerResponse) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(r, r.Extra)
+}
@@ -0,0 +1,135 @@
+package andromeda
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtraUnmarshal(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		want  GetCheckPanicResponse
+		extra []string //Ключи Extra
+	}{
+		{
+			name:  "неописанные поля попадают в Extra",
+			body:  `{"Status":1,"Description":"ok","Foo":"bar","List":[1,2]}`,
+			want:  GetCheckPanicResponse{Status: 1, Description: "ok"},
+			extra: []string{"Foo", "List"},
+		},
+		{
+			name: "поле в другом регистре считается описанным",
+			body: `{"status":2,"DESCRIPTION":"ok"}`,
+			want: GetCheckPanicResponse{Status: 2, Description: "ok"},
+		},
+		{name: "пустой объект", body: `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got GetCheckPanicResponse
+			if err := json.Unmarshal([]byte(tt.body), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != tt.want.Status || got.Description != tt.want.Description {
+				t.Fatalf("ответ %+v, ожидался %+v", got, tt.want)
+			}
+			if len(got.Extra) != len(tt.extra) {
+				t.Fatalf("Extra %v, ожидались ключи %v", got.Extra, tt.extra)
+			}
+			for _, key := range tt.extra {
+				if _, ok := got.Extra[key]; !ok {
+					t.Errorf("в Extra нет ключа %s", key)
+				}
+			}
+		})
+	}
+}
+
+func TestExtraMarshal(t *testing.T) {
+	tests := []struct {
+		name  string
+		extra map[string]json.RawMessage
+		want  string
+	}{
+		{name: "без Extra", want: `{"Status":1,"Description":"ok"}`},
+		{
+			name:  "поля Extra дописываются по алфавиту",
+			extra: map[string]json.RawMessage{"Zeta": json.RawMessage(`true`), "Alpha": json.RawMessage(`{"a":1}`)},
+			want:  `{"Status":1,"Description":"ok","Alpha":{"a":1},"Zeta":true}`,
+		},
+		{
+			name: "поля Extra не перезаписывают и не дублируют описанные поля",
+			extra: map[string]json.RawMessage{
+				"Status":      json.RawMessage(`9`),
+				"description": json.RawMessage(`"подмена"`),
+				"Foo":         json.RawMessage(`1`),
+			},
+			want: `{"Status":1,"Description":"ok","Foo":1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(GetCheckPanicResponse{Status: 1, Description: "ok", Extra: tt.extra})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Fatalf("%s, ожидалось %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtraRoundTrip(t *testing.T) {
+	const unknown = `"Unknown":{"Nested":[1,"два"]}`
+
+	tests := []struct {
+		name string
+		v    any //Указатель на структуру ответа
+		body string
+	}{
+		{name: "PostCheckPanicResponse", v: &PostCheckPanicResponse{}, body: `{"Description":"ok",` + unknown + `}`},
+		{name: "PutChangeUserMyAlarmResponse", v: &PutChangeUserMyAlarmResponse{}, body: `{"Message":"ok",` + unknown + `}`},
+		{name: "GetCheckPanicResponse", v: &GetCheckPanicResponse{}, body: `{"Status":1,` + unknown + `}`},
+		{name: "UserMyAlarmResponse", v: &UserMyAlarmResponse{}, body: `{"CustomerID":"c1",` + unknown + `}`},
+		{name: "GetUserObjectMyAlarmResponse", v: &GetUserObjectMyAlarmResponse{}, body: `{"ObjectGUID":"s1",` + unknown + `}`},
+		{name: "GetPartsResponse", v: &GetPartsResponse{}, body: `{"Id":"p1",` + unknown + `}`},
+		{name: "GetZonesResponse", v: &GetZonesResponse{}, body: `{"Id":"z1",` + unknown + `}`},
+		{name: "GetSitesResponse", v: &GetSitesResponse{}, body: `{"Id":"s1","ContractPrice":1500.5,` + unknown + `}`},
+		{name: "GetCustomerResponse", v: &GetCustomerResponse{}, body: `{"Id":"c1",` + unknown + `}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := json.Unmarshal([]byte(tt.body), tt.v); err != nil {
+				t.Fatal(err)
+			}
+			extra := reflect.ValueOf(tt.v).Elem().FieldByName("Extra").Interface().(map[string]json.RawMessage)
+			if len(extra) != 1 || string(extra["Unknown"]) != `{"Nested":[1,"два"]}` {
+				t.Fatalf("Extra %v, ожидалось только поле Unknown", extra)
+			}
+
+			data, err := json.Marshal(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Count(string(data), `"Unknown"`) != 1 || !strings.HasSuffix(string(data), ","+unknown+"}") {
+				t.Fatalf("%s: поле Unknown не записано", data)
+			}
+
+			// Повторный разбор записанного ответа даёт ту же структуру
+			again := reflect.New(reflect.TypeOf(tt.v).Elem()).Interface()
+			if err := json.Unmarshal(data, again); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(again, tt.v) {
+				t.Fatalf("после повторного разбора %+v, ожидалось %+v", again, tt.v)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package andromeda
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultReprobeInterval = time.Minute
+
+type (
+	//Параметры группы серверов с резервированием
+	FailoverOptions struct {
+		Hosts           []string            //Адреса серверов в порядке предпочтения, первый - основной
+		ReprobeInterval time.Duration       //Через какое время после сбоя основного сервера снова пробовать его первым (по умолчанию 1 минута)
+		OnFailover      func(FailoverEvent) //Вызывается при переключении на другой сервер (необязательное поле)
+	}
+
+	//Переключение группы на другой сервер
+	FailoverEvent struct {
+		From      string    //Сервер, на котором произошёл сбой (или резервный сервер при возврате на основной)
+		To        string    //Сервер, на который переключились запросы
+		Err       error     //Ошибка, из-за которой произошло переключение; nil при возврате на основной сервер
+		Recovered bool      //Возврат на основной сервер после его восстановления
+		Time      time.Time //Время переключения
+	}
+
+	//Состояние сервера группы
+	HostStatus struct {
+		Host        string
+		Primary     bool      //Основной сервер группы
+		Active      bool      //Запросы сейчас направляются на этот сервер
+		Healthy     bool      //Последний запрос к серверу выполнен без сбоя
+		Failures    int       //Сбоев подряд
+		LastError   string    //Последняя ошибка
+		LastFailure time.Time //Время последнего сбоя
+	}
+
+	failover struct {
+		opts FailoverOptions
+
+		mu     sync.Mutex
+		hosts  []*HostStatus
+		errs   []error //Последняя ошибка каждого сервера
+		active int
+	}
+)
+
+// Резервирование серверов: запросы с Config.Host из opts.Hosts направляются на последний исправный сервер группы.
+// GET запрос при любой ошибке соединения или ответе 5xx повторяется на следующем сервере группы.
+// POST и PUT запросы повторяются, только если запрос не был отправлен (сервер недоступен, не установлено соединение
+// или открыт автомат защиты): после отправки сервер мог их уже выполнить, даже если ответ не получен.
+// Ответ 5xx на запрос любого метода отмечает сервер как неисправный.
+// Основной сервер снова пробуется первым, если с момента его сбоя прошло ReprobeInterval
+func WithFailover(opts FailoverOptions) Option {
+	return func(c *Client) {
+		if len(opts.Hosts) == 0 {
+			return
+		}
+		if opts.ReprobeInterval <= 0 {
+			opts.ReprobeInterval = defaultReprobeInterval
+		}
+		f := &failover{opts: opts}
+		for idx, host := range opts.Hosts {
+			f.hosts = append(f.hosts, &HostStatus{Host: host, Primary: idx == 0, Healthy: true})
+		}
+		f.errs = make([]error, len(f.hosts))
+		c.failovers = append(c.failovers, f)
+	}
+}
+
+// Состояние серверов всех групп с резервированием
+func (c *Client) HostHealth() []HostStatus {
+	var status []HostStatus
+	for _, f := range c.failovers {
+		f.mu.Lock()
+		for idx, h := range f.hosts {
+			st := *h
+			st.Active = idx == f.active
+			status = append(status, st)
+		}
+		f.mu.Unlock()
+	}
+
+	return status
+}
+
+// Группа, в которую входит адрес сервера
+func (c *Client) failoverFor(host string) *failover {
+	for _, f := range c.failovers {
+		for _, h := range f.opts.Hosts {
+			if sameHost(h, host) {
+				return f
+			}
+		}
+	}
+
+	return nil
+}
+
+// Выполнение запроса на серверах группы по очереди до первого ответа без сбоя.
+// Если сбой произошёл на всех серверах, возвращается результат последней попытки
+func (f *failover) do(ctx context.Context, c *Client, method string, r request) (*http.Response, error) {
+	order := f.order(time.Now())
+
+	var (
+		resp *http.Response
+		err  error
+	)
+	for n, idx := range order {
+		host := f.opts.Hosts[idx]
+		resp, err = c.do(ctx, method, strings.TrimRight(host, "/")+r.path, r)
+		if ctx.Err() != nil {
+			return resp, err
+		}
+
+		var failure error
+		switch {
+		case err != nil:
+			failure = err
+		case resp.StatusCode >= http.StatusInternalServerError:
+			failure = &APIError{Code: CodeHTTPStatus, StatusCode: resp.StatusCode, ContentType: resp.Header.Get("Content-Type"), Lang: c.lang}
+		}
+		if failure == nil {
+			f.success(idx)
+			return resp, nil
+		}
+
+		f.failure(idx, failure)
+		if method != http.MethodGet && !notSent(err) {
+			return resp, err
+		}
+		if n < len(order)-1 && resp != nil {
+			drainBody(resp.Body)
+		}
+	}
+
+	return resp, err
+}
+
+// Признак того, что запрос не дошёл до сервера: ошибка установки TCP или TLS соединения или открытый автомат защиты
+func notSent(err error) bool {
+	var (
+		opErr  *net.OpError
+		tlsErr *TLSError
+	)
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, ErrCircuitOpen), errors.As(err, &tlsErr):
+		return true
+	case errors.As(err, &opErr):
+		return opErr.Op == "dial"
+	}
+
+	return false
+}
+
+// Порядок опроса серверов: основной (если пора его проверить), текущий, остальные по порядку
+func (f *failover) order(now time.Time) []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	order := make([]int, 0, len(f.hosts))
+	seen := make([]bool, len(f.hosts))
+	add := func(idx int) {
+		if !seen[idx] {
+			seen[idx] = true
+			order = append(order, idx)
+		}
+	}
+
+	if now.Sub(f.hosts[0].LastFailure) >= f.opts.ReprobeInterval {
+		add(0)
+	}
+	add(f.active)
+	for idx := range f.hosts {
+		add(idx)
+	}
+
+	return order
+}
+
+func (f *failover) success(idx int) {
+	f.mu.Lock()
+	h := f.hosts[idx]
+	h.Healthy = true
+	h.Failures = 0
+
+	prev := f.active
+	f.active = idx
+	prevErr := f.errs[prev]
+	f.mu.Unlock()
+
+	if prev != idx && f.opts.OnFailover != nil {
+		event := FailoverEvent{From: f.opts.Hosts[prev], To: f.opts.Hosts[idx], Time: time.Now()}
+		if idx == 0 {
+			event.Recovered = true
+		} else {
+			event.Err = prevErr
+		}
+		f.opts.OnFailover(event)
+	}
+}
+
+func (f *failover) failure(idx int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	h := f.hosts[idx]
+	h.Healthy = false
+	h.Failures++
+	h.LastError = err.Error()
+	h.LastFailure = time.Now()
+	f.errs[idx] = err
+}
+
+// Сравнение адресов серверов без учёта регистра и завершающей косой черты
+func sameHost(a, b string) bool {
+	return strings.EqualFold(strings.TrimRight(a, "/"), strings.TrimRight(b, "/"))
+}
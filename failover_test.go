@@ -0,0 +1,128 @@
+package andromeda
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func statusHandler(status int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}
+}
+
+// Обработчик, получающий запрос и не отвечающий дольше таймаута клиента
+func hangHandler(w http.ResponseWriter, r *http.Request) {
+	select {
+	case <-r.Context().Done():
+	case <-time.After(time.Second):
+	}
+}
+
+// Адрес, на котором гарантированно никто не слушает
+func closedAddr(t *testing.T) string {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	return srv.URL
+}
+
+func TestFailover(t *testing.T) {
+	const checkPanic = `{"Status":1,"Description":"ok","CheckPanicId":"c1"}`
+
+	tests := []struct {
+		name        string
+		primary     http.HandlerFunc //nil - основной сервер недоступен
+		method      string
+		wantBackup  int32
+		wantErr     bool
+		wantPrimary int32
+	}{
+		{
+			name:        "POST не повторяется после таймаута ответа",
+			primary:     hangHandler,
+			method:      http.MethodPost,
+			wantPrimary: 1,
+			wantErr:     true,
+		},
+		{
+			name:        "POST не повторяется после ответа 5xx",
+			primary:     statusHandler(http.StatusBadGateway),
+			method:      http.MethodPost,
+			wantPrimary: 1,
+			wantErr:     true,
+		},
+		{
+			name:       "POST повторяется, если основной сервер недоступен",
+			method:     http.MethodPost,
+			wantBackup: 1,
+		},
+		{
+			name:        "GET повторяется после ответа 5xx",
+			primary:     statusHandler(http.StatusInternalServerError),
+			method:      http.MethodGet,
+			wantPrimary: 1,
+			wantBackup:  1,
+		},
+		{
+			name:        "GET повторяется после таймаута ответа",
+			primary:     hangHandler,
+			method:      http.MethodGet,
+			wantPrimary: 1,
+			wantBackup:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backupBody := `{"Id":"1"}`
+			if tt.method == http.MethodPost {
+				backupBody = checkPanic
+			}
+			backup := newCountingServer(t, jsonHandler(backupBody))
+
+			primaryURL := closedAddr(t)
+			var primary *countingServer
+			if tt.primary != nil {
+				primary = newCountingServer(t, tt.primary)
+				primaryURL = primary.URL
+			}
+
+			var events []FailoverEvent
+			c := NewClient(WithFailover(FailoverOptions{
+				Hosts:      []string{primaryURL, backup.URL},
+				OnFailover: func(e FailoverEvent) { events = append(events, e) },
+			}))
+			c.client.Timeout = 200 * time.Millisecond
+
+			cfg := Config{Host: primaryURL, ApiKey: "key"}
+			var err error
+			if tt.method == http.MethodPost {
+				_, err = c.PostCheckPanic(context.Background(), PostCheckPanicInput{SiteId: "s1", Config: cfg})
+			} else {
+				_, err = c.GetSites(context.Background(), GetSitesInput{Id: "1", Config: cfg})
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ошибка %v, ожидалась ошибка: %v", err, tt.wantErr)
+			}
+			if got := backup.hits.Load(); got != tt.wantBackup {
+				t.Errorf("запросов к резервному серверу %d, ожидалось %d", got, tt.wantBackup)
+			}
+			if primary != nil {
+				if got := primary.hits.Load(); got != tt.wantPrimary {
+					t.Errorf("запросов к основному серверу %d, ожидалось %d", got, tt.wantPrimary)
+				}
+			}
+			if wantEvents := int(tt.wantBackup); len(events) != wantEvents {
+				t.Errorf("событий переключения %d, ожидалось %d", len(events), wantEvents)
+			}
+			// Во всех случаях основной сервер дал сбой, в том числе ответ 5xx на POST без повтора
+			if st := c.HostHealth()[0]; st.Healthy || st.Failures != 1 {
+				t.Errorf("состояние основного сервера %+v, ожидался один сбой", st)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package gateway
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	siteCacheTTL  = 10 * time.Minute
+	siteCacheSize = 10000
+	checkTTL      = time.Hour
+	checkLimit    = 10000
+)
+
+type (
+	//Кеш с ограничением размера и временем жизни записей
+	ttlCache[V any] struct {
+		ttl time.Duration
+		max int
+		now func() time.Time
+
+		mu      sync.Mutex
+		entries map[string]ttlEntry[V]
+	}
+
+	ttlEntry[V any] struct {
+		value   V
+		expires time.Time
+	}
+)
+
+func newTTLCache[V any](ttl time.Duration, max int) *ttlCache[V] {
+	return &ttlCache[V]{ttl: ttl, max: max, now: time.Now, entries: map[string]ttlEntry[V]{}}
+}
+
+func (c *ttlCache[V]) get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || !c.now().Before(e.expires) {
+		var zero V
+		return zero, false
+	}
+
+	return e.value, true
+}
+
+// Добавление записей. При заполнении кеша удаляются устаревшие записи, а если их нет - произвольные
+func (c *ttlCache[V]) put(value V, keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if len(c.entries)+len(keys) > c.max {
+		for key, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, key)
+			}
+		}
+	}
+	for key := range c.entries {
+		if len(c.entries)+len(keys) <= c.max {
+			break
+		}
+		delete(c.entries, key)
+	}
+
+	for _, key := range keys {
+		c.entries[key] = ttlEntry[V]{value: value, expires: now.Add(c.ttl)}
+	}
+}
+
+func (c *ttlCache[V]) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
@@ -0,0 +1,530 @@
+// Пакет gateway предоставляет методы SDK как JSON REST API с собственными токенами доступа.
+// Каждый токен ограничен набором прав и списком объектов, а имя токена передаётся в Андромеду
+// как UserName всех запросов, поэтому API ключ Андромеды не покидает шлюз.
+//
+//	GET  /v1/sites/{site}                                      sites:read
+//	GET  /v1/sites/{site}/parts                                sites:read
+//	GET  /v1/sites/{site}/zones                                sites:read
+//	GET  /v1/sites/{site}/customers                            customers:read
+//	GET  /v1/sites/{site}/customers/{customer}                 customers:read
+//	GET  /v1/sites/{site}/myalarm-users                        customers:read
+//	POST /v1/sites/{site}/panic-checks                         panic:check
+//	GET  /v1/panic-checks/{check}                              panic:check
+//	PUT  /v1/sites/{site}/customers/{customer}/myalarm-role    myalarm:write
+//	PUT  /v1/sites/{site}/customers/{customer}/myalarm-panic   myalarm:write
+//
+// {site} - номер или идентификатор объекта. Доступ к объекту проверяется по списку токена до запроса к Андромеде,
+// поэтому для недоступного объекта ответ 403 не зависит от того, существует ли объект.
+// Без права sites:secrets пароль объекта и PIN коды маскируются. Поля ответа Андромеды, не описанные в SDK,
+// клиенту не передаются: шлюз не знает, что в них, и не может их маскировать.
+// Ошибки Андромеды передаются клиенту только кодом и сообщением из каталога SDK.
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+const (
+	maxRequestBody = 64 << 10
+	masked         = "***"
+)
+
+type (
+	//Методы SDK, которые использует шлюз. Реализуется *andromeda.Client
+	API interface {
+		GetSites(ctx context.Context, input andromeda.GetSitesInput) (andromeda.GetSitesResponse, error)
+		Customers(ctx context.Context, input andromeda.GetCustomersInput) ([]andromeda.GetCustomerResponse, error)
+		GetParts(ctx context.Context, input andromeda.GetPartsInput) ([]andromeda.GetPartsResponse, error)
+		GetZones(ctx context.Context, input andromeda.GetZonesInput) ([]andromeda.GetZonesResponse, error)
+		GetUsersMyAlarm(ctx context.Context, input andromeda.GetUsersMyAlarmInput) ([]andromeda.UserMyAlarmResponse, error)
+		PostCheckPanic(ctx context.Context, input andromeda.PostCheckPanicInput) (andromeda.PostCheckPanicResponse, error)
+		GetCheckPanic(ctx context.Context, input andromeda.GetCheckPanicInput) (andromeda.GetCheckPanicResponse, error)
+		PutChangeUserMyAlarm(ctx context.Context, input andromeda.PutChangeUserMyAlarmInput) (andromeda.PutChangeUserMyAlarmResponse, error)
+		PutChangeKTSUserMyAlarm(ctx context.Context, input andromeda.PutChangeKTSUserMyAlarmInput) error
+	}
+
+	//HTTP сервер шлюза
+	Server struct {
+		client API
+		config andromeda.Config
+		tokens *tokenSet
+		mux    *http.ServeMux
+
+		sites  *ttlCache[siteRef] //Объекты по номеру или идентификатору
+		checks *ttlCache[string]  //Владелец проверки КТС по идентификатору проверки
+	}
+
+	//Идентификатор и номер объекта
+	siteRef struct {
+		id     string
+		number int
+	}
+
+	//Ошибка запроса к шлюзу. Сообщение выбирается по коду на языке из заголовка Accept-Language
+	Error struct {
+		Status       int                    `json:"-"`
+		Code         andromeda.ErrorCode    `json:"code"`
+		Message      string                 `json:"message"`
+		Detail       string                 `json:"detail,omitempty"` //Поле, право или идентификатор, к которому относится ошибка
+		Fields       []andromeda.FieldError `json:"fields,omitempty"`
+		SpResultCode int                    `json:"spResultCode,omitempty"`
+	}
+
+	handlerFunc func(ctx context.Context, tok *Token, r *http.Request) (any, error)
+)
+
+func (e *Error) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return andromeda.Localize(&andromeda.Error{Code: e.Code, Detail: e.Detail}, andromeda.LangRU).Error()
+}
+
+// Ответ с ошибкой на языке lang
+func (e *Error) Localize(lang andromeda.Lang) error {
+	cp := *e
+	if cp.Message == "" {
+		cp.Message = andromeda.Localize(&andromeda.Error{Code: e.Code, Detail: e.Detail}, lang).Error()
+	}
+	if len(e.Fields) > 0 {
+		cp.Fields = andromeda.Localize(&andromeda.ValidationError{Fields: e.Fields}, lang).(*andromeda.ValidationError).Fields
+	}
+
+	return &cp
+}
+
+func newError(status int, code andromeda.ErrorCode, detail string) *Error {
+	return &Error{Status: status, Code: code, Detail: detail}
+}
+
+// Создание шлюза. cfg - адрес сервера Андромеды и API ключ, которыми выполняются все запросы
+func New(client API, cfg andromeda.Config, tokens []Token) (*Server, error) {
+	set, err := newTokenSet(tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		client: client,
+		config: cfg,
+		tokens: set,
+		mux:    http.NewServeMux(),
+		sites:  newTTLCache[siteRef](siteCacheTTL, siteCacheSize),
+		checks: newTTLCache[string](checkTTL, checkLimit),
+	}
+
+	s.handle("GET /v1/sites/{site}", ScopeSitesRead, s.getSite)
+	s.handle("GET /v1/sites/{site}/parts", ScopeSitesRead, s.getParts)
+	s.handle("GET /v1/sites/{site}/zones", ScopeSitesRead, s.getZones)
+	s.handle("GET /v1/sites/{site}/customers", ScopeCustomersRead, s.getCustomers)
+	s.handle("GET /v1/sites/{site}/customers/{customer}", ScopeCustomersRead, s.getCustomer)
+	s.handle("GET /v1/sites/{site}/myalarm-users", ScopeCustomersRead, s.getMyAlarmUsers)
+	s.handle("POST /v1/sites/{site}/panic-checks", ScopePanicCheck, s.postPanicCheck)
+	s.handle("GET /v1/panic-checks/{check}", ScopePanicCheck, s.getPanicCheck)
+	s.handle("PUT /v1/sites/{site}/customers/{customer}/myalarm-role", ScopeMyAlarmWrite, s.putMyAlarmRole)
+	s.handle("PUT /v1/sites/{site}/customers/{customer}/myalarm-panic", ScopeMyAlarmWrite, s.putMyAlarmPanic)
+
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Регистрация обработчика с проверкой токена и права scope
+func (s *Server) handle(pattern string, scope Scope, fn handlerFunc) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		value, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || value == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="andromeda-gateway"`)
+			writeError(w, r, newError(http.StatusUnauthorized, andromeda.CodeUnauthorized, ""))
+			return
+		}
+		tok, ok := s.tokens.lookup(strings.TrimSpace(value))
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="andromeda-gateway", error="invalid_token"`)
+			writeError(w, r, newError(http.StatusUnauthorized, andromeda.CodeUnauthorized, ""))
+			return
+		}
+		if !tok.has(scope) {
+			writeError(w, r, newError(http.StatusForbidden, andromeda.CodeForbidden, string(scope)))
+			return
+		}
+
+		out, err := fn(r.Context(), tok, r)
+		if err != nil {
+			writeError(w, r, apiError(err))
+			return
+		}
+		writeJSON(w, http.StatusOK, out)
+	})
+}
+
+// Параметры Андромеды для запроса от имени токена
+func (s *Server) input(tok *Token) (andromeda.Config, string) {
+	return s.config, tok.Name
+}
+
+// Объект из адреса запроса с проверкой доступа токена
+func (s *Server) site(ctx context.Context, tok *Token, r *http.Request) (siteRef, error) {
+	param := r.PathValue("site")
+	if err := s.authorize(ctx, tok, param); err != nil {
+		return siteRef{}, err
+	}
+
+	ref, err := s.resolve(ctx, tok, param)
+	if err != nil {
+		return siteRef{}, err
+	}
+	if !tok.allows(ref.id, ref.number) {
+		return siteRef{}, forbiddenSite()
+	}
+
+	return ref, nil
+}
+
+// Проверка, что param - номер или идентификатор объекта из списка токена. Если объект задан в списке в другой форме
+// (например, номером, а в адресе идентификатор), запрашиваются объекты из списка токена, но не param
+func (s *Server) authorize(ctx context.Context, tok *Token, param string) error {
+	if tok.allows(param, 0) {
+		return nil
+	}
+
+	var upstreamErr error
+	for _, entry := range tok.Sites {
+		ref, err := s.resolve(ctx, tok, entry)
+		if err != nil {
+			// Объект из списка мог быть удалён; прочие ошибки не зависят от param и возвращаются как есть
+			if andromeda.CodeOf(err) != andromeda.CodeServerError {
+				upstreamErr = err
+			}
+			continue
+		}
+		if strings.EqualFold(ref.id, param) || strconv.Itoa(ref.number) == param {
+			return nil
+		}
+	}
+	if upstreamErr != nil {
+		return upstreamErr
+	}
+
+	return forbiddenSite()
+}
+
+// Идентификатор и номер объекта из кеша или из карточки объекта
+func (s *Server) resolve(ctx context.Context, tok *Token, key string) (siteRef, error) {
+	if ref, ok := s.sites.get(strings.ToLower(key)); ok {
+		return ref, nil
+	}
+
+	site, err := s.fetchSite(ctx, tok, key)
+	if err != nil {
+		return siteRef{}, err
+	}
+
+	return siteRef{id: site.Id, number: site.AccountNumber}, nil
+}
+
+// Запрос карточки объекта и запоминание его идентификатора и номера
+func (s *Server) fetchSite(ctx context.Context, tok *Token, param string) (andromeda.GetSitesResponse, error) {
+	cfg, user := s.input(tok)
+	site, err := s.client.GetSites(ctx, andromeda.GetSitesInput{Id: param, UserName: user, Config: cfg})
+	if err != nil {
+		return site, err
+	}
+
+	s.sites.put(siteRef{id: site.Id, number: site.AccountNumber},
+		strings.ToLower(param), strings.ToLower(site.Id), strconv.Itoa(site.AccountNumber))
+
+	return site, nil
+}
+
+func (s *Server) getSite(ctx context.Context, tok *Token, r *http.Request) (any, error) {
+	param := r.PathValue("site")
+	if err := s.authorize(ctx, tok, param); err != nil {
+		return nil, err
+	}
+
+	site, err := s.fetchSite(ctx, tok, param)
+	if err != nil {
+		return nil, err
+	}
+	if !tok.allows(site.Id, site.AccountNumber) {
+		return nil, forbiddenSite()
+	}
+	if !tok.has(ScopeSecretsRead) && site.ObjectPassword != "" {
+		site.ObjectPassword = masked
+	}
+	site.Extra = nil
+
+	return site, nil
+}
+
+func (s *Server) getParts(ctx context.Context, tok *Token, r *http.Request) (any, error) {
+	ref, err := s.site(ctx, tok, r)
+	if err != nil {
+		return nil, err
+	}
+	cfg, user := s.input(tok)
+
+	list, err := s.client.GetParts(ctx, andromeda.GetPartsInput{SiteId: ref.id, UserName: user, Config: cfg})
+	for idx := range list {
+		list[idx].Extra = nil
+	}
+
+	return list, err
+}
+
+func (s *Server) getZones(ctx context.Context, tok *Token, r *http.Request) (any, error) {
+	ref, err := s.site(ctx, tok, r)
+	if err != nil {
+		return nil, err
+	}
+	cfg, user := s.input(tok)
+
+	list, err := s.client.GetZones(ctx, andromeda.GetZonesInput{SiteId: ref.id, UserName: user, Config: cfg})
+	for idx := range list {
+		list[idx].Extra = nil
+	}
+
+	return list, err
+}
+
+func (s *Server) getCustomers(ctx context.Context, tok *Token, r *http.Request) (any, error) {
+	ref, err := s.site(ctx, tok, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.customers(ctx, tok, ref)
+}
+
+func (s *Server) getCustomer(ctx context.Context, tok *Token, r *http.Request) (any, error) {
+	ref, err := s.site(ctx, tok, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.customer(ctx, tok, ref, r.PathValue("customer"))
+}
+
+func (s *Server) getMyAlarmUsers(ctx context.Context, tok *Token, r *http.Request) (any, error) {
+	ref, err := s.site(ctx, tok, r)
+	if err != nil {
+		return nil, err
+	}
+	cfg, user := s.input(tok)
+
+	list, err := s.client.GetUsersMyAlarm(ctx, andromeda.GetUsersMyAlarmInput{SiteId: ref.id, UserName: user, Config: cfg})
+	for idx := range list {
+		list[idx].Extra = nil
+	}
+
+	return list, err
+}
+
+func (s *Server) postPanicCheck(ctx context.Context, tok *Token, r *http.Request) (any, error) {
+	ref, err := s.site(ctx, tok, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var body struct {
+		CheckInterval int `json:"checkInterval"`
+	}
+	if err := decodeBody(r, &body, false); err != nil {
+		return nil, err
+	}
+
+	cfg, user := s.input(tok)
+	res, err := s.client.PostCheckPanic(ctx, andromeda.PostCheckPanicInput{
+		SiteId:        ref.id,
+		CheckInterval: body.CheckInterval,
+		UserName:      user,
+		Config:        cfg,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if res.CheckPanicId != "" {
+		s.checks.put(tok.Name, res.CheckPanicId)
+	}
+	res.Extra = nil
+
+	return res, nil
+}
+
+// Результат проверки КТС доступен только токену, который её запустил
+func (s *Server) getPanicCheck(ctx context.Context, tok *Token, r *http.Request) (any, error) {
+	id := r.PathValue("check")
+
+	if owner, _ := s.checks.get(id); owner != tok.Name {
+		return nil, newError(http.StatusNotFound, andromeda.CodeNotFound, id)
+	}
+
+	cfg, user := s.input(tok)
+	res, err := s.client.GetCheckPanic(ctx, andromeda.GetCheckPanicInput{CheckPanicId: id, UserName: user, Config: cfg})
+	res.Extra = nil
+
+	return res, err
+}
+
+func (s *Server) putMyAlarmRole(ctx context.Context, tok *Token, r *http.Request) (any, error) {
+	ref, err := s.site(ctx, tok, r)
+	if err != nil {
+		return nil, err
+	}
+	var body struct {
+		Role string `json:"role"`
+	}
+	if err := decodeBody(r, &body, true); err != nil {
+		return nil, err
+	}
+	custId := r.PathValue("customer")
+	if _, err := s.customer(ctx, tok, ref, custId); err != nil {
+		return nil, err
+	}
+
+	cfg, user := s.input(tok)
+	res, err := s.client.PutChangeUserMyAlarm(ctx, andromeda.PutChangeUserMyAlarmInput{CustId: custId, Role: body.Role, UserName: user, Config: cfg})
+	res.Extra = nil
+
+	return res, err
+}
+
+func (s *Server) putMyAlarmPanic(ctx context.Context, tok *Token, r *http.Request) (any, error) {
+	ref, err := s.site(ctx, tok, r)
+	if err != nil {
+		return nil, err
+	}
+	var body struct {
+		IsPanic *bool `json:"isPanic"`
+	}
+	if err := decodeBody(r, &body, true); err != nil {
+		return nil, err
+	}
+	if body.IsPanic == nil {
+		return nil, newError(http.StatusBadRequest, andromeda.CodeBadRequest, "isPanic")
+	}
+	custId := r.PathValue("customer")
+	if _, err := s.customer(ctx, tok, ref, custId); err != nil {
+		return nil, err
+	}
+
+	cfg, user := s.input(tok)
+	err = s.client.PutChangeKTSUserMyAlarm(ctx, andromeda.PutChangeKTSUserMyAlarmInput{CustId: custId, IsPanic: *body.IsPanic, UserName: user, Config: cfg})
+	if err != nil {
+		return nil, err
+	}
+
+	return struct {
+		IsPanic bool `json:"isPanic"`
+	}{*body.IsPanic}, nil
+}
+
+// Ответственные лица объекта с маскированием PIN кодов и без полей, не описанных в SDK
+func (s *Server) customers(ctx context.Context, tok *Token, ref siteRef) ([]andromeda.GetCustomerResponse, error) {
+	cfg, user := s.input(tok)
+	list, err := s.client.Customers(ctx, andromeda.GetCustomersInput{SiteId: ref.id, UserName: user, Config: cfg})
+	if err != nil {
+		return nil, err
+	}
+	for idx := range list {
+		if !tok.has(ScopeSecretsRead) && list[idx].PINCode != "" {
+			list[idx].PINCode = masked
+		}
+		list[idx].Extra = nil
+	}
+
+	return list, nil
+}
+
+// Ответственное лицо объекта. Лицо другого объекта не найдено, даже если оно существует
+func (s *Server) customer(ctx context.Context, tok *Token, ref siteRef, custId string) (andromeda.GetCustomerResponse, error) {
+	list, err := s.customers(ctx, tok, ref)
+	if err != nil {
+		return andromeda.GetCustomerResponse{}, err
+	}
+	for _, c := range list {
+		if strings.EqualFold(c.Id, custId) {
+			return c, nil
+		}
+	}
+
+	return andromeda.GetCustomerResponse{}, newError(http.StatusNotFound, andromeda.CodeNotFound, custId)
+}
+
+func forbiddenSite() error {
+	return newError(http.StatusForbidden, andromeda.CodeForbidden, "")
+}
+
+// Разбор тела запроса. Неизвестные поля не допускаются
+func decodeBody(r *http.Request, v any, required bool) error {
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxRequestBody))
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(v)
+	if err == nil || (!required && errors.Is(err, io.EOF)) {
+		return nil
+	}
+
+	return newError(http.StatusBadRequest, andromeda.CodeBadRequest, err.Error())
+}
+
+// Ответ шлюза по ошибке SDK
+func apiError(err error) *Error {
+	var (
+		gwErr    *Error
+		validErr *andromeda.ValidationError
+		apiErr   *andromeda.APIError
+	)
+	switch {
+	case errors.As(err, &gwErr):
+		return gwErr
+	case errors.As(err, &validErr):
+		return &Error{Status: http.StatusBadRequest, Code: andromeda.CodeValidationFailed, Fields: validErr.Fields}
+	case errors.As(err, &apiErr) && apiErr.Code == andromeda.CodeServerError:
+		return &Error{Status: http.StatusUnprocessableEntity, Code: apiErr.Code, SpResultCode: apiErr.SpResultCode}
+	case errors.Is(err, andromeda.ErrCircuitOpen):
+		return newError(http.StatusServiceUnavailable, andromeda.CodeCircuitOpen, "")
+	case errors.Is(err, context.DeadlineExceeded):
+		return newError(http.StatusGatewayTimeout, andromeda.CodeTimeout, "")
+	}
+
+	code := andromeda.CodeOf(err)
+	if code == "" {
+		code = andromeda.CodeUpstream
+	}
+
+	return newError(http.StatusBadGateway, code, "")
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, e *Error) {
+	writeJSON(w, e.Status, struct {
+		Error error `json:"error"`
+	}{e.Localize(requestLang(r))})
+}
+
+// Язык ответа по заголовку Accept-Language: английский, если он указан первым, иначе русский
+func requestLang(r *http.Request) andromeda.Lang {
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(r.Header.Get("Accept-Language"))), "en") {
+		return andromeda.LangEN
+	}
+
+	return andromeda.LangRU
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
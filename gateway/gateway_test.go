@@ -0,0 +1,373 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+var secretExtra = map[string]json.RawMessage{"Secret": json.RawMessage(`"s3cr3t"`)}
+
+// Андромеда в памяти: объекты по номеру и идентификатору и счётчик запросов карточек
+type fakeAPI struct {
+	mu      sync.Mutex
+	sites   []andromeda.GetSitesResponse
+	lookups map[string]int //Запросы GetSites по значению Id
+	err     error          //Ошибка всех запросов, если задана
+}
+
+func newFakeAPI() *fakeAPI {
+	return &fakeAPI{
+		sites: []andromeda.GetSitesResponse{
+			{Id: "site-1", AccountNumber: 101, ObjectPassword: "pass1", Extra: secretExtra},
+			{Id: "site-2", AccountNumber: 102, ObjectPassword: "pass2", Extra: secretExtra},
+		},
+		lookups: map[string]int{},
+	}
+}
+
+func (f *fakeAPI) calls(id string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lookups[id]
+}
+
+func (f *fakeAPI) GetSites(ctx context.Context, in andromeda.GetSitesInput) (andromeda.GetSitesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.lookups[in.Id]++
+	if f.err != nil {
+		return andromeda.GetSitesResponse{}, f.err
+	}
+	for _, s := range f.sites {
+		if strings.EqualFold(s.Id, in.Id) || strconv.Itoa(s.AccountNumber) == in.Id {
+			return s, nil
+		}
+	}
+
+	return andromeda.GetSitesResponse{}, &andromeda.APIError{Code: andromeda.CodeServerError, StatusCode: 400, Message: "Объект " + in.Id + " не найден", SpResultCode: 7}
+}
+
+func (f *fakeAPI) Customers(ctx context.Context, in andromeda.GetCustomersInput) ([]andromeda.GetCustomerResponse, error) {
+	return []andromeda.GetCustomerResponse{{Id: "cust-" + in.SiteId, PINCode: "1234", Extra: secretExtra}}, nil
+}
+
+func (f *fakeAPI) GetParts(ctx context.Context, in andromeda.GetPartsInput) ([]andromeda.GetPartsResponse, error) {
+	return []andromeda.GetPartsResponse{{Id: "part", Extra: secretExtra}}, nil
+}
+
+func (f *fakeAPI) GetZones(ctx context.Context, in andromeda.GetZonesInput) ([]andromeda.GetZonesResponse, error) {
+	return []andromeda.GetZonesResponse{{Id: "zone", Extra: secretExtra}}, nil
+}
+
+func (f *fakeAPI) GetUsersMyAlarm(ctx context.Context, in andromeda.GetUsersMyAlarmInput) ([]andromeda.UserMyAlarmResponse, error) {
+	return []andromeda.UserMyAlarmResponse{{CustomerID: "cust-" + in.SiteId, Extra: secretExtra}}, nil
+}
+
+func (f *fakeAPI) PostCheckPanic(ctx context.Context, in andromeda.PostCheckPanicInput) (andromeda.PostCheckPanicResponse, error) {
+	return andromeda.PostCheckPanicResponse{CheckPanicId: "check-" + in.SiteId, Extra: secretExtra}, nil
+}
+
+func (f *fakeAPI) GetCheckPanic(ctx context.Context, in andromeda.GetCheckPanicInput) (andromeda.GetCheckPanicResponse, error) {
+	return andromeda.GetCheckPanicResponse{Description: "ok", Extra: secretExtra}, nil
+}
+
+func (f *fakeAPI) PutChangeUserMyAlarm(ctx context.Context, in andromeda.PutChangeUserMyAlarmInput) (andromeda.PutChangeUserMyAlarmResponse, error) {
+	return andromeda.PutChangeUserMyAlarmResponse{Message: "ok", Extra: secretExtra}, nil
+}
+
+func (f *fakeAPI) PutChangeKTSUserMyAlarm(ctx context.Context, in andromeda.PutChangeKTSUserMyAlarmInput) error {
+	return nil
+}
+
+var testTokens = []Token{
+	{Name: "reader", Token: "reader-token", Scopes: []Scope{ScopeSitesRead, ScopeCustomersRead, ScopePanicCheck}, Sites: []string{"101"}},
+	{Name: "by-id", Token: "by-id-token", Scopes: []Scope{ScopeSitesRead}, Sites: []string{"SITE-1"}},
+	{Name: "admin", Token: "admin-token", Scopes: []Scope{ScopeSitesRead, ScopeSecretsRead, ScopeCustomersRead, ScopePanicCheck}, Sites: []string{AllSites}},
+	{Name: "no-scopes", Token: "no-scopes-token", Sites: []string{AllSites}},
+}
+
+func newTestServer(t *testing.T, api API) *Server {
+	t.Helper()
+
+	s, err := New(api, andromeda.Config{Host: "https://andromeda.local", ApiKey: "key"}, testTokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// Запрос к шлюзу: статус и тело ответа
+func serve(s *Server, method, path, token, body string) (int, string) {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+
+	return w.Code, w.Body.String()
+}
+
+func TestServerAccess(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		path    string
+		status  int
+		noFetch string //Значение, по которому не должно быть запроса к Андромеде
+	}{
+		{name: "без токена", path: "/v1/sites/101", status: http.StatusUnauthorized, noFetch: "101"},
+		{name: "неизвестный токен", token: "wrong", path: "/v1/sites/101", status: http.StatusUnauthorized, noFetch: "101"},
+		{name: "нет права", token: "no-scopes-token", path: "/v1/sites/101", status: http.StatusForbidden, noFetch: "101"},
+		{name: "нет права на разделы", token: "by-id-token", path: "/v1/sites/site-1/customers", status: http.StatusForbidden, noFetch: "site-1"},
+		{name: "объект по номеру", token: "reader-token", path: "/v1/sites/101", status: http.StatusOK},
+		{name: "объект из списка номером запрошен по идентификатору", token: "reader-token", path: "/v1/sites/site-1/parts", status: http.StatusOK, noFetch: "site-1"},
+		{name: "объект из списка идентификатором запрошен по номеру", token: "by-id-token", path: "/v1/sites/101/zones", status: http.StatusOK, noFetch: "101"},
+		{name: "чужой объект по номеру", token: "reader-token", path: "/v1/sites/102", status: http.StatusForbidden, noFetch: "102"},
+		{name: "чужой объект по идентификатору", token: "by-id-token", path: "/v1/sites/site-2/zones", status: http.StatusForbidden, noFetch: "site-2"},
+		{name: "несуществующий объект", token: "reader-token", path: "/v1/sites/999/customers", status: http.StatusForbidden, noFetch: "999"},
+		{name: "все объекты", token: "admin-token", path: "/v1/sites/site-2", status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			s := newTestServer(t, api)
+
+			status, body := serve(s, http.MethodGet, tt.path, tt.token, "")
+			if status != tt.status {
+				t.Fatalf("статус %d, ожидался %d: %s", status, tt.status, body)
+			}
+			if tt.noFetch != "" && api.calls(tt.noFetch) != 0 {
+				t.Fatalf("объект %s запрошен у Андромеды", tt.noFetch)
+			}
+		})
+	}
+}
+
+// Ответ на запрос чужого объекта не отличается от ответа на запрос несуществующего
+func TestServerForbiddenIndistinguishable(t *testing.T) {
+	s := newTestServer(t, newFakeAPI())
+
+	_, existing := serve(s, http.MethodGet, "/v1/sites/102", "reader-token", "")
+	_, missing := serve(s, http.MethodGet, "/v1/sites/999", "reader-token", "")
+	if existing != missing {
+		t.Fatalf("ответы различаются:\n%s\n%s", existing, missing)
+	}
+}
+
+func TestServerRedaction(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		method  string
+		path    string
+		secret  string //Значение, которое не должно попасть в ответ
+		visible string //Значение, которое должно быть в ответе
+	}{
+		{name: "пароль объекта маскируется", token: "reader-token", path: "/v1/sites/101", secret: "pass1", visible: masked},
+		{name: "пароль объекта с правом sites:secrets", token: "admin-token", path: "/v1/sites/101", visible: "pass1"},
+		{name: "PIN код маскируется", token: "reader-token", path: "/v1/sites/101/customers", secret: "1234", visible: masked},
+		{name: "PIN код с правом sites:secrets", token: "admin-token", path: "/v1/sites/101/customers/cust-site-1", visible: "1234"},
+		{name: "неописанные поля карточки", token: "admin-token", path: "/v1/sites/101", secret: "s3cr3t"},
+		{name: "неописанные поля ответственных", token: "admin-token", path: "/v1/sites/101/customers", secret: "s3cr3t"},
+		{name: "неописанные поля разделов", token: "reader-token", path: "/v1/sites/101/parts", secret: "s3cr3t"},
+		{name: "неописанные поля шлейфов", token: "reader-token", path: "/v1/sites/101/zones", secret: "s3cr3t"},
+		{name: "неописанные поля MyAlarm", token: "reader-token", path: "/v1/sites/101/myalarm-users", secret: "s3cr3t"},
+		{name: "неописанные поля проверки КТС", token: "reader-token", method: http.MethodPost, path: "/v1/sites/101/panic-checks", secret: "s3cr3t"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, newFakeAPI())
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			status, body := serve(s, method, tt.path, tt.token, "")
+			if status != http.StatusOK {
+				t.Fatalf("статус %d: %s", status, body)
+			}
+			if tt.secret != "" && strings.Contains(body, tt.secret) {
+				t.Fatalf("ответ содержит %q: %s", tt.secret, body)
+			}
+			if tt.visible != "" && !strings.Contains(body, tt.visible) {
+				t.Fatalf("ответ не содержит %q: %s", tt.visible, body)
+			}
+		})
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   andromeda.ErrorCode
+	}{
+		{
+			name:   "ошибка сервера Андромеды",
+			err:    &andromeda.APIError{Code: andromeda.CodeServerError, StatusCode: 400, Message: "internal: table Sites", SpResultCode: 3},
+			status: http.StatusUnprocessableEntity,
+			code:   andromeda.CodeServerError,
+		},
+		{
+			name:   "ошибка соединения",
+			err:    &andromeda.Error{Code: andromeda.CodeRequestFailed, Err: errors.New("dial tcp https://andromeda.local: internal")},
+			status: http.StatusBadGateway,
+			code:   andromeda.CodeRequestFailed,
+		},
+		{
+			name:   "выключатель разомкнут",
+			err:    andromeda.ErrCircuitOpen,
+			status: http.StatusServiceUnavailable,
+			code:   andromeda.CodeCircuitOpen,
+		},
+		{
+			name:   "таймаут",
+			err:    context.DeadlineExceeded,
+			status: http.StatusGatewayTimeout,
+			code:   andromeda.CodeTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			api.err = tt.err
+			s := newTestServer(t, api)
+
+			status, body := serve(s, http.MethodGet, "/v1/sites/101", "admin-token", "")
+			if status != tt.status {
+				t.Fatalf("статус %d, ожидался %d: %s", status, tt.status, body)
+			}
+			var resp struct {
+				Error Error `json:"error"`
+			}
+			if err := json.Unmarshal([]byte(body), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Error.Code != tt.code {
+				t.Fatalf("код %q, ожидался %q", resp.Error.Code, tt.code)
+			}
+			if strings.Contains(body, "internal") {
+				t.Fatalf("ответ содержит текст ошибки Андромеды: %s", body)
+			}
+		})
+	}
+}
+
+// Результат проверки КТС доступен только токену, который её запустил
+func TestServerPanicCheckOwner(t *testing.T) {
+	s := newTestServer(t, newFakeAPI())
+
+	if status, body := serve(s, http.MethodPost, "/v1/sites/101/panic-checks", "reader-token", ""); status != http.StatusOK {
+		t.Fatalf("статус %d: %s", status, body)
+	}
+	if status, _ := serve(s, http.MethodGet, "/v1/panic-checks/check-site-1", "admin-token", ""); status != http.StatusNotFound {
+		t.Fatalf("чужая проверка: статус %d, ожидался 404", status)
+	}
+	if status, body := serve(s, http.MethodGet, "/v1/panic-checks/check-site-1", "reader-token", ""); status != http.StatusOK {
+		t.Fatalf("своя проверка: статус %d: %s", status, body)
+	}
+}
+
+func TestTTLCache(t *testing.T) {
+	c := newTTLCache[int](time.Minute, 3)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	c.put(1, "a", "b")
+	c.put(2, "c", "d")
+	if c.len() > 3 {
+		t.Fatalf("записей %d при ограничении 3", c.len())
+	}
+	if v, ok := c.get("d"); !ok || v != 2 {
+		t.Fatalf("последняя запись потеряна: %d, %v", v, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := c.get("d"); ok {
+		t.Fatal("устаревшая запись не удалена")
+	}
+	c.put(3, "e", "f", "g")
+	if c.len() != 3 {
+		t.Fatalf("устаревшие записи не вытеснены: %d", c.len())
+	}
+}
+
+func TestNewTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		tokens []Token
+		code   andromeda.ErrorCode
+	}{
+		{name: "без имени", tokens: []Token{{Token: "t"}}, code: andromeda.CodeTokenName},
+		{name: "повтор имени", tokens: []Token{{Name: "a", Token: "t1"}, {Name: "a", Token: "t2"}}, code: andromeda.CodeTokenDuplicate},
+		{name: "без значения", tokens: []Token{{Name: "a"}}, code: andromeda.CodeTokenValue},
+		{name: "неверный хеш", tokens: []Token{{Name: "a", TokenSHA256: "abc"}}, code: andromeda.CodeTokenValue},
+		{name: "неизвестное право", tokens: []Token{{Name: "a", Token: "t", Scopes: []Scope{"sites:write"}}}, code: andromeda.CodeTokenScope},
+		{name: "хеш вместо значения", tokens: []Token{{Name: "a", TokenSHA256: HashToken("t"), Scopes: []Scope{ScopeSitesRead}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(newFakeAPI(), andromeda.Config{}, tt.tokens)
+			if got := andromeda.CodeOf(err); got != tt.code {
+				t.Fatalf("код ошибки %q, ожидался %q (%v)", got, tt.code, err)
+			}
+		})
+	}
+}
+
+func TestLoadTokens(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"tokens.yaml": "- name: a\n  token: t\n  scopes: [sites:read]\n  sites: [\"101\"]\n",
+		"tokens.json": `[{"name":"a","token":"t","scopes":["sites:read"],"sites":["101"]}]`,
+		"tokens.txt":  "a",
+		"broken.json": "[",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		file string
+		code andromeda.ErrorCode
+	}{
+		{file: "tokens.yaml"},
+		{file: "tokens.json"},
+		{file: "tokens.txt", code: andromeda.CodeFileFormat},
+		{file: "broken.json", code: andromeda.CodeFileParse},
+		{file: "missing.json", code: andromeda.CodeFileRead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			tokens, err := LoadTokens(filepath.Join(dir, tt.file))
+			if got := andromeda.CodeOf(err); got != tt.code {
+				t.Fatalf("код ошибки %q, ожидался %q (%v)", got, tt.code, err)
+			}
+			if tt.code == "" && (len(tokens) != 1 || tokens[0].Sites[0] != "101") {
+				t.Fatalf("токены прочитаны неверно: %+v", tokens)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package gateway
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ScopeSitesRead     Scope = "sites:read"     //Карточки объектов, разделы и шлейфы
+	ScopeSecretsRead   Scope = "sites:secrets"  //Пароль объекта и PIN коды ответственных без маскирования
+	ScopeCustomersRead Scope = "customers:read" //Ответственные лица и пользователи MyAlarm
+	ScopePanicCheck    Scope = "panic:check"    //Проверка КТС
+	ScopeMyAlarmWrite  Scope = "myalarm:write"  //Изменение ролей и разрешения КТС пользователей MyAlarm
+)
+
+// Значение списка объектов, разрешающее доступ ко всем объектам
+const AllSites = "*"
+
+type (
+	//Право доступа токена
+	Scope string
+
+	//Токен доступа к шлюзу
+	Token struct {
+		Name        string   `json:"name" yaml:"name"`                                   //Имя клиента; передаётся в Андромеду как UserName всех запросов
+		Token       string   `json:"token,omitempty" yaml:"token,omitempty"`             //Значение токена
+		TokenSHA256 string   `json:"tokenSha256,omitempty" yaml:"tokenSha256,omitempty"` //SHA-256 токена в hex, если значение не хранится в файле
+		Scopes      []Scope  `json:"scopes" yaml:"scopes"`                               //Права доступа
+		Sites       []string `json:"sites" yaml:"sites"`                                 //Номера или идентификаторы доступных объектов, «*» - все объекты
+	}
+
+	//Проверка токенов
+	tokenSet struct {
+		tokens []Token
+		hashes [][]byte
+	}
+)
+
+// Чтение токенов из файла JSON или YAML
+func LoadTokens(path string) ([]Token, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &andromeda.Error{Code: andromeda.CodeFileRead, Detail: path, Err: err}
+	}
+
+	var tokens []Token
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &tokens)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tokens)
+	default:
+		return nil, &andromeda.Error{Code: andromeda.CodeFileFormat, Detail: path}
+	}
+	if err != nil {
+		return nil, &andromeda.Error{Code: andromeda.CodeFileParse, Detail: path, Err: err}
+	}
+
+	return tokens, nil
+}
+
+// SHA-256 токена в hex для поля TokenSHA256
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newTokenSet(tokens []Token) (*tokenSet, error) {
+	set := &tokenSet{}
+	names := map[string]bool{}
+	for idx, t := range tokens {
+		if t.Name == "" {
+			return nil, &andromeda.Error{Code: andromeda.CodeTokenName, Detail: "#" + strconv.Itoa(idx+1)}
+		}
+		if names[t.Name] {
+			return nil, &andromeda.Error{Code: andromeda.CodeTokenDuplicate, Detail: t.Name}
+		}
+		names[t.Name] = true
+
+		hash := t.TokenSHA256
+		if t.Token != "" {
+			hash = HashToken(t.Token)
+		}
+		raw, err := hex.DecodeString(hash)
+		if err != nil || len(raw) != sha256.Size {
+			return nil, &andromeda.Error{Code: andromeda.CodeTokenValue, Detail: t.Name}
+		}
+		for _, scope := range t.Scopes {
+			switch scope {
+			case ScopeSitesRead, ScopeSecretsRead, ScopeCustomersRead, ScopePanicCheck, ScopeMyAlarmWrite:
+			default:
+				return nil, &andromeda.Error{Code: andromeda.CodeTokenScope, Detail: t.Name + ": " + string(scope)}
+			}
+		}
+
+		t.Token = ""
+		set.tokens = append(set.tokens, t)
+		set.hashes = append(set.hashes, raw)
+	}
+
+	return set, nil
+}
+
+// Поиск токена по значению. Сравнение хешей выполняется за постоянное время
+func (s *tokenSet) lookup(value string) (*Token, bool) {
+	sum := sha256.Sum256([]byte(value))
+
+	found := -1
+	for idx, hash := range s.hashes {
+		if subtle.ConstantTimeCompare(sum[:], hash) == 1 {
+			found = idx
+		}
+	}
+	if found < 0 {
+		return nil, false
+	}
+
+	return &s.tokens[found], true
+}
+
+func (t *Token) has(scope Scope) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Доступ к объекту с идентификатором id и номером number
+func (t *Token) allows(id string, number int) bool {
+	for _, site := range t.Sites {
+		if site == AllSites || strings.EqualFold(site, id) || (number != 0 && site == strconv.Itoa(number)) {
+			return true
+		}
+	}
+
+	return false
+}
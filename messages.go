@@ -0,0 +1,356 @@
+package andromeda
+
+import (
+	"errors"
+)
+
+const (
+	LangRU Lang = "ru" //Русский (по умолчанию)
+	LangEN Lang = "en" //Английский
+)
+
+// Коды ошибок SDK. Значения не меняются между версиями и подходят для анализа логов
+const (
+	CodeValidationFailed   ErrorCode = "validation_failed"
+	CodeSiteNumberRequired ErrorCode = "site_number_required"
+	CodeSiteIdRequired     ErrorCode = "site_id_required"
+	CodeCustomerIdRequired ErrorCode = "customer_id_required"
+	CodeUserIdRequired     ErrorCode = "user_id_required"
+	CodeCheckIdRequired    ErrorCode = "check_id_required"
+	CodeCheckIntervalRange ErrorCode = "check_interval_range"
+	CodePhoneRequired      ErrorCode = "phone_required"
+	CodePhoneFormat        ErrorCode = "phone_format"
+	CodeRoleInvalid        ErrorCode = "role_invalid"
+	CodeApiKeyRequired     ErrorCode = "api_key_required"
+	CodeHostRequired       ErrorCode = "host_required"
+	CodeHostInvalid        ErrorCode = "host_invalid"
+
+	CodeRequestBuildFailed ErrorCode = "request_build_failed"
+	CodeRequestFailed      ErrorCode = "request_failed"
+	CodeServerError        ErrorCode = "server_error"
+	CodeHTTPStatus         ErrorCode = "http_status"
+	CodeUnexpectedContent  ErrorCode = "unexpected_content"
+	CodeResponseReadFailed ErrorCode = "response_read_failed"
+	CodeResponseDecode     ErrorCode = "response_decode_failed"
+	CodeResponseNotArray   ErrorCode = "response_not_array"
+	CodeResponseTooLarge   ErrorCode = "response_too_large"
+	CodeSchemaMismatch     ErrorCode = "schema_mismatch"
+	CodeSchemaUnknown      ErrorCode = "schema_unknown_field"
+	CodeSchemaMissing      ErrorCode = "schema_missing_field"
+	CodeSchemaType         ErrorCode = "schema_type_mismatch"
+
+	CodeCircuitOpen   ErrorCode = "circuit_open"
+	CodeDryRun        ErrorCode = "dry_run"
+	CodeBulkSkipped   ErrorCode = "bulk_skipped"
+	CodeSiteFetch     ErrorCode = "site_fetch_failed"
+	CodeCustomerFetch ErrorCode = "customer_fetch_failed"
+
+	CodeCredentialsFailed ErrorCode = "credentials_failed"
+	CodeEnvNotSet         ErrorCode = "env_not_set"
+	CodeKeyFileRead       ErrorCode = "key_file_read_failed"
+	CodeKeyFileEmpty      ErrorCode = "key_file_empty"
+
+	CodeTLS                 ErrorCode = "tls_error"
+	CodeTLSPinMismatch      ErrorCode = "tls_pin_mismatch"
+	CodeTLSUnknownAuthority ErrorCode = "tls_unknown_authority"
+	CodeTLSHostname         ErrorCode = "tls_hostname_mismatch"
+	CodeTLSExpired          ErrorCode = "tls_certificate_expired"
+	CodeTLSInvalid          ErrorCode = "tls_certificate_invalid"
+	CodeTLSVerifyFailed     ErrorCode = "tls_verify_failed"
+	CodeTLSRejected         ErrorCode = "tls_rejected"
+	CodeTLSNotTLS           ErrorCode = "tls_not_tls"
+	CodeTLSCARead           ErrorCode = "tls_ca_read_failed"
+	CodeTLSCAEmpty          ErrorCode = "tls_ca_empty"
+	CodeTLSClientCert       ErrorCode = "tls_client_cert_failed"
+	CodeTLSPinInvalid       ErrorCode = "tls_pin_invalid"
+	CodeDateFormat          ErrorCode = "date_format"
+	CodeMoneyFormat         ErrorCode = "money_format"
+
+	//Подпакеты SDK
+	CodeCustomersFetch    ErrorCode = "customers_fetch_failed"
+	CodePartsFetch        ErrorCode = "parts_fetch_failed"
+	CodeZonesFetch        ErrorCode = "zones_fetch_failed"
+	CodeMyAlarmUsersFetch ErrorCode = "myalarm_users_fetch_failed"
+	CodeFileRead          ErrorCode = "file_read_failed"
+	CodeFileWrite         ErrorCode = "file_write_failed"
+	CodeFileFormat        ErrorCode = "file_format_unknown"
+	CodeFileParse         ErrorCode = "file_parse_failed"
+	CodeReportWrite       ErrorCode = "report_write_failed"
+	CodeExportField       ErrorCode = "export_field_unknown"
+	CodeDBOpen            ErrorCode = "db_open_failed"
+	CodeDBSchema          ErrorCode = "db_schema_failed"
+	CodeDBRead            ErrorCode = "db_read_failed"
+	CodeDBWrite           ErrorCode = "db_write_failed"
+	CodeClientRequired    ErrorCode = "client_required"
+	CodeUpdaterRequired   ErrorCode = "site_updater_required"
+	CodePeriodInvalid     ErrorCode = "period_invalid"
+	CodeTaskFailed        ErrorCode = "task_failed"
+	CodeNotConfirmed      ErrorCode = "not_confirmed"
+	CodeApplyFailed       ErrorCode = "apply_failed"
+	CodeServerUnknown     ErrorCode = "server_unknown"
+	CodeServerName        ErrorCode = "server_name_invalid"
+	CodeServerDuplicate   ErrorCode = "server_duplicate"
+	CodeTenantUnknown     ErrorCode = "tenant_unknown"
+	CodeTenantDuplicate   ErrorCode = "tenant_duplicate"
+	CodeSiteUnmapped      ErrorCode = "site_unmapped"
+	CodeSiteNotFound      ErrorCode = "site_not_found"
+	CodeSiteAmbiguous     ErrorCode = "site_ambiguous"
+	CodeAuditLogOpen      ErrorCode = "audit_log_open_failed"
+	CodeAuditLogClosed    ErrorCode = "audit_log_closed"
+	CodeAuditLogWrite     ErrorCode = "audit_log_write_failed"
+	CodeAuditLogRead      ErrorCode = "audit_log_read_failed"
+	CodeAuditHash         ErrorCode = "audit_hash_failed"
+	CodeAuditTampered     ErrorCode = "audit_log_tampered"
+	CodeAuditPrevHash     ErrorCode = "audit_prev_hash_mismatch"
+	CodeAuditRecordHash   ErrorCode = "audit_record_hash_mismatch"
+	CodeAuditRecordFormat ErrorCode = "audit_record_format"
+	CodeAuditHeadHash     ErrorCode = "audit_head_hash_mismatch"
+	CodeCassetteFormat    ErrorCode = "cassette_format"
+	CodeTokenName         ErrorCode = "token_name_required"
+	CodeTokenDuplicate    ErrorCode = "token_name_duplicate"
+	CodeTokenValue        ErrorCode = "token_value_invalid"
+	CodeTokenScope        ErrorCode = "token_scope_unknown"
+	CodeUnauthorized      ErrorCode = "unauthorized"
+	CodeForbidden         ErrorCode = "forbidden"
+	CodeNotFound          ErrorCode = "not_found"
+	CodeBadRequest        ErrorCode = "bad_request"
+	CodeTimeout           ErrorCode = "timeout"
+	CodeUpstream          ErrorCode = "upstream_error"
+)
+
+type (
+	//Язык сообщений SDK
+	Lang string
+
+	//Код ошибки SDK
+	ErrorCode string
+
+	//Ошибка SDK с кодом. Сообщение выбирается по коду на языке Lang
+	Error struct {
+		Code   ErrorCode
+		Detail string //Дополнительные сведения: адрес сервера, значение поля, имя файла (необязательное поле)
+		Err    error  //Причина (необязательное поле)
+		Lang   Lang   //Язык сообщения, по умолчанию русский
+	}
+)
+
+// Сообщения по коду ошибки: русский и английский
+var catalog = map[ErrorCode][2]string{
+	CodeValidationFailed:   {"неверно заполнены входные данные", "invalid input"},
+	CodeSiteNumberRequired: {"неверно задан номер объекта", "site number is not set"},
+	CodeSiteIdRequired:     {"неверно задан идентификатор объекта", "site id is not set"},
+	CodeCustomerIdRequired: {"неверно задан идентификатор ответственного лица", "customer id is not set"},
+	CodeUserIdRequired:     {"неверно задан идентификатор пользователя", "user id is not set"},
+	CodeCheckIdRequired:    {"неверно задан идентификатор проверки", "check id is not set"},
+	CodeCheckIntervalRange: {"неверно задано время ожидания проверки", "check interval must be between 31 and 179 seconds"},
+	CodePhoneRequired:      {"неверно задан номер телефона", "phone number is not set"},
+	CodePhoneFormat:        {"неверно задан номер телефона", "phone number must be in +7XXXXXXXXXX format"},
+	CodeRoleInvalid:        {"неверно задана роль пользователя", "user role must be one of admin, user, unlink"},
+	CodeApiKeyRequired:     {"неверно задан API ключ", "API key is not set"},
+	CodeHostRequired:       {"неверно задан адрес сервера", "server address is not set"},
+	CodeHostInvalid:        {"неверно задан адрес сервера", "invalid server address"},
+
+	CodeRequestBuildFailed: {"не удалось создать запрос", "failed to build request"},
+	CodeRequestFailed:      {"не удалось выполнить запрос", "request failed"},
+	CodeServerError:        {"сервер вернул ошибку", "server returned an error"},
+	CodeHTTPStatus:         {"не удалось выполнить запрос: HTTP", "request failed: HTTP"},
+	CodeUnexpectedContent:  {"неожиданный формат ответа", "unexpected response format"},
+	CodeResponseReadFailed: {"не удалось прочитать ответ", "failed to read response"},
+	CodeResponseDecode:     {"не удалось парсить ответ", "failed to decode response"},
+	CodeResponseNotArray:   {"не удалось парсить ответ: ожидался массив", "failed to decode response: array expected"},
+	CodeResponseTooLarge:   {"превышен максимальный размер ответа", "response size limit exceeded"},
+	CodeSchemaMismatch:     {"ответ не соответствует структуре", "response does not match the SDK structure"},
+	CodeSchemaUnknown:      {"неизвестное поле", "unknown field"},
+	CodeSchemaMissing:      {"отсутствует поле", "missing field"},
+	CodeSchemaType:         {"неверный тип поля, ожидался", "wrong field type, expected"},
+
+	CodeCircuitOpen:   {"сервер недоступен: запросы временно не выполняются", "server unavailable: requests are temporarily suspended"},
+	CodeDryRun:        {"запрос не выполнен: пробный запуск", "request not sent: dry run"},
+	CodeBulkSkipped:   {"операция не выполнена: пакетная обработка остановлена", "operation skipped: bulk processing stopped"},
+	CodeSiteFetch:     {"не удалось получить объект", "failed to get site"},
+	CodeCustomerFetch: {"не удалось получить ответственное лицо", "failed to get customer"},
+
+	CodeCredentialsFailed: {"не удалось получить API ключ", "failed to get API key"},
+	CodeEnvNotSet:         {"не задана переменная окружения", "environment variable is not set"},
+	CodeKeyFileRead:       {"не удалось прочитать файл API ключа", "failed to read API key file"},
+	CodeKeyFileEmpty:      {"файл API ключа пуст", "API key file is empty"},
+
+	CodeTLS:                 {"ошибка TLS соединения", "TLS connection error"},
+	CodeTLSPinMismatch:      {"открытый ключ сервера не совпадает с закреплённым", "server public key does not match the pinned key"},
+	CodeTLSUnknownAuthority: {"сертификат сервера выдан неизвестным центром сертификации", "server certificate is signed by an unknown authority"},
+	CodeTLSHostname:         {"сертификат сервера выдан для другого имени", "server certificate is issued for a different name"},
+	CodeTLSExpired:          {"срок действия сертификата сервера истёк или ещё не наступил", "server certificate has expired or is not yet valid"},
+	CodeTLSInvalid:          {"сертификат сервера недействителен", "server certificate is invalid"},
+	CodeTLSVerifyFailed:     {"не удалось проверить сертификат сервера", "failed to verify server certificate"},
+	CodeTLSRejected:         {"сервер отклонил соединение (возможно, требуется сертификат клиента или другая версия TLS)", "server rejected the connection (a client certificate or another TLS version may be required)"},
+	CodeTLSNotTLS:           {"сервер ответил не по протоколу TLS", "server did not respond with TLS"},
+	CodeTLSCARead:           {"не удалось прочитать корневые сертификаты", "failed to read CA certificates"},
+	CodeTLSCAEmpty:          {"не найдено ни одного корневого сертификата в формате PEM", "no PEM CA certificates found"},
+	CodeTLSClientCert:       {"не удалось загрузить сертификат клиента", "failed to load client certificate"},
+	CodeTLSPinInvalid:       {"неверно задан закреплённый ключ", "invalid pinned key"},
+	CodeDateFormat:          {"неверный формат даты", "invalid date format"},
+	CodeMoneyFormat:         {"неверно задана денежная сумма", "invalid money amount"},
+
+	CodeCustomersFetch:    {"не удалось получить ответственных", "failed to get customers"},
+	CodePartsFetch:        {"не удалось получить разделы", "failed to get parts"},
+	CodeZonesFetch:        {"не удалось получить шлейфы", "failed to get zones"},
+	CodeMyAlarmUsersFetch: {"не удалось получить пользователей MyAlarm", "failed to get MyAlarm users"},
+	CodeFileRead:          {"не удалось прочитать файл", "failed to read file"},
+	CodeFileWrite:         {"не удалось записать файл", "failed to write file"},
+	CodeFileFormat:        {"неизвестный формат файла", "unknown file format"},
+	CodeFileParse:         {"не удалось парсить файл", "failed to parse file"},
+	CodeReportWrite:       {"не удалось записать отчёт", "failed to write report"},
+	CodeExportField:       {"неизвестное поле для выгрузки", "unknown export field"},
+	CodeDBOpen:            {"не удалось открыть базу", "failed to open database"},
+	CodeDBSchema:          {"не удалось создать схему базы", "failed to create database schema"},
+	CodeDBRead:            {"не удалось прочитать базу", "failed to read database"},
+	CodeDBWrite:           {"не удалось записать в базу", "failed to write database"},
+	CodeClientRequired:    {"не задан клиент для получения объектов", "client is not set"},
+	CodeUpdaterRequired:   {"изменение объектов не поддерживается: не задан SiteUpdater", "site changes are not supported: SiteUpdater is not set"},
+	CodePeriodInvalid:     {"неверно задан период отключения", "invalid disable period"},
+	CodeTaskFailed:        {"не удалось выполнить задачу", "task failed"},
+	CodeNotConfirmed:      {"применение плана не подтверждено", "plan application is not confirmed"},
+	CodeApplyFailed:       {"не удалось применить изменения", "failed to apply changes"},
+	CodeServerUnknown:     {"сервер не зарегистрирован", "server is not registered"},
+	CodeServerName:        {"неверно задано имя сервера", "invalid server name"},
+	CodeServerDuplicate:   {"сервер уже зарегистрирован", "server is already registered"},
+	CodeTenantUnknown:     {"арендатор не привязан ни к одному серверу", "tenant is not mapped to any server"},
+	CodeTenantDuplicate:   {"арендатор уже привязан к другому серверу", "tenant is already mapped to another server"},
+	CodeSiteUnmapped:      {"объект не привязан ни к одному серверу", "site is not mapped to any server"},
+	CodeSiteNotFound:      {"объект не найден ни на одном сервере", "site is not found on any server"},
+	CodeSiteAmbiguous:     {"объект найден на нескольких серверах", "site is found on several servers"},
+	CodeAuditLogOpen:      {"не удалось открыть журнал", "failed to open audit log"},
+	CodeAuditLogClosed:    {"журнал закрыт", "audit log is closed"},
+	CodeAuditLogWrite:     {"не удалось записать журнал", "failed to write audit log"},
+	CodeAuditLogRead:      {"не удалось прочитать журнал", "failed to read audit log"},
+	CodeAuditHash:         {"не удалось вычислить хеш записи", "failed to hash audit record"},
+	CodeAuditTampered:     {"журнал изменён: строка", "audit log tampered: line"},
+	CodeAuditPrevHash:     {"хеш предыдущей записи не совпадает", "previous record hash mismatch"},
+	CodeAuditRecordHash:   {"хеш записи не совпадает с содержимым", "record hash does not match its content"},
+	CodeAuditRecordFormat: {"запись не в формате JSON", "record is not valid JSON"},
+	CodeAuditHeadHash:     {"последняя запись не совпадает с сохранённым хешем", "last record does not match the saved head hash"},
+	CodeCassetteFormat:    {"неверный формат записи", "invalid cassette format"},
+	CodeTokenName:         {"не задано имя токена", "token name is not set"},
+	CodeTokenDuplicate:    {"имя токена уже используется", "token name is already used"},
+	CodeTokenValue:        {"не задано значение токена или неверно задан SHA-256", "token value is not set or SHA-256 is invalid"},
+	CodeTokenScope:        {"неизвестное право токена", "unknown token scope"},
+	CodeUnauthorized:      {"требуется действительный токен доступа", "a valid access token is required"},
+	CodeForbidden:         {"нет доступа", "access denied"},
+	CodeNotFound:          {"не найдено", "not found"},
+	CodeBadRequest:        {"неверный запрос", "bad request"},
+	CodeTimeout:           {"истекло время ожидания ответа сервера Андромеда", "Andromeda server timed out"},
+	CodeUpstream:          {"ошибка запроса к серверу Андромеда", "Andromeda request failed"},
+}
+
+// Язык сообщений об ошибках клиента (по умолчанию русский)
+func WithLanguage(lang Lang) Option {
+	return func(c *Client) {
+		c.lang = lang
+	}
+}
+
+// Сообщение по коду на языке lang. Для неизвестного языка используется русский
+func (code ErrorCode) Message(lang Lang) string {
+	msgs, ok := catalog[code]
+	if !ok {
+		return string(code)
+	}
+	if lang == LangEN {
+		return msgs[1]
+	}
+
+	return msgs[0]
+}
+
+func (e *Error) Error() string {
+	msg := e.Code.Message(e.Lang)
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Ошибки SDK равны, если совпадают коды: errors.Is(err, ErrCircuitOpen)
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Код первой ошибки SDK в цепочке err; пустая строка, если ошибка не из SDK
+func CodeOf(err error) ErrorCode {
+	for err != nil {
+		switch e := err.(type) {
+		case *Error:
+			return e.Code
+		case *ValidationError:
+			return CodeValidationFailed
+		case *APIError:
+			return e.Code
+		case *TLSError:
+			return e.Code
+		case *SchemaError:
+			return CodeSchemaMismatch
+		case *DryRunError:
+			return CodeDryRun
+		}
+		err = errors.Unwrap(err)
+	}
+
+	return ""
+}
+
+// Ошибка с кодом на языке клиента. Вложенные ошибки SDK переводятся на тот же язык
+func (c *Client) error(code ErrorCode, err error, detail string) *Error {
+	return &Error{Code: code, Detail: detail, Err: Localize(err, c.lang), Lang: c.lang}
+}
+
+// Перевод ошибки SDK и вложенных в неё ошибок SDK на язык lang. Исходная ошибка не изменяется.
+// Функции без клиента (ParseMoney, ParseDate, источники ключей, подпакеты SDK) возвращают ошибки на русском;
+// ошибки подпакетов переводятся, если реализуют метод Localize(Lang) error
+func Localize(err error, lang Lang) error {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *Error:
+		cp := *e
+		cp.Lang = lang
+		cp.Err = Localize(e.Err, lang)
+		return &cp
+	case *ValidationError:
+		cp := &ValidationError{Fields: make([]FieldError, len(e.Fields))}
+		for idx, f := range e.Fields {
+			f.Message = f.Code.Message(lang)
+			cp.Fields[idx] = f
+		}
+		return cp
+	case *APIError:
+		cp := *e
+		cp.Lang = lang
+		return &cp
+	case *TLSError:
+		cp := *e
+		cp.Lang = lang
+		cp.Reason = e.Code.Message(lang)
+		return &cp
+	case *SchemaError:
+		cp := *e
+		cp.Lang = lang
+		return &cp
+	case *DryRunError:
+		cp := *e
+		cp.Lang = lang
+		return &cp
+	case interface{ Localize(Lang) error }:
+		return e.Localize(lang)
+	}
+
+	return err
+}
@@ -0,0 +1,108 @@
+package andromeda
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+)
+
+var cyrillic = regexp.MustCompile(`[А-Яа-яЁё]`)
+
+func TestClientErrorLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		opts []Option
+		code ErrorCode
+	}{
+		{
+			name: "строгий режим",
+			body: `{"Id":"1","NewField":1}`,
+			opts: []Option{WithStrictDecoding(StrictOptions{Fail: true})},
+			code: CodeSchemaMismatch,
+		},
+		{
+			name: "неверная денежная сумма",
+			body: `{"Id":"1","ContractPrice":"abc"}`,
+			code: CodeResponseDecode,
+		},
+		{
+			name: "источник ключа",
+			body: `{"Id":"1"}`,
+			opts: []Option{WithCredentials(EnvCredentials{Name: "ANDROMEDA_TEST_UNSET_KEY"})},
+			code: CodeCredentialsFailed,
+		},
+		{
+			name: "проверка входных данных",
+			body: `{"Id":"1"}`,
+			code: CodeValidationFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newCountingServer(t, jsonHandler(tt.body))
+
+			c := NewClient(append(tt.opts, WithLanguage(LangEN))...)
+			in := GetSitesInput{Id: "1", Config: Config{Host: srv.URL, ApiKey: "key"}}
+			if tt.code == CodeValidationFailed {
+				in.Id = ""
+			}
+			_, err := c.GetSites(context.Background(), in)
+
+			if got := CodeOf(err); got != tt.code {
+				t.Fatalf("код ошибки %q, ожидался %q (%v)", got, tt.code, err)
+			}
+			if cyrillic.MatchString(err.Error()) {
+				t.Fatalf("сообщение не на английском: %v", err)
+			}
+		})
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	_, parseErr := ParseMoney("abc")
+	orig := &Error{Code: CodeRequestFailed, Err: parseErr}
+
+	en := Localize(orig, LangEN)
+	if cyrillic.MatchString(en.Error()) {
+		t.Fatalf("сообщение не на английском: %v", en)
+	}
+	if !cyrillic.MatchString(orig.Error()) {
+		t.Fatalf("исходная ошибка изменена: %v", orig)
+	}
+	if !errors.Is(en, &Error{Code: CodeMoneyFormat}) {
+		t.Fatalf("после перевода потерян код вложенной ошибки: %v", en)
+	}
+	if Localize(nil, LangEN) != nil {
+		t.Fatal("перевод nil должен возвращать nil")
+	}
+	if CodeOf(Localize(ErrCircuitOpen, LangEN)) != CodeCircuitOpen || ErrCircuitOpen.Lang != "" {
+		t.Fatal("перевод общей ошибки не должен её изменять")
+	}
+}
+
+func TestCatalogComplete(t *testing.T) {
+	for code, msgs := range catalog {
+		if msgs[0] == "" || msgs[1] == "" {
+			t.Errorf("нет сообщения для кода %s", code)
+		}
+		if cyrillic.MatchString(msgs[1]) {
+			t.Errorf("английское сообщение кода %s содержит кириллицу", code)
+		}
+		// Параметры дописываются к сообщению вызывающим кодом, а не подставляются в шаблон
+		if strings.Contains(msgs[0]+msgs[1], "%") {
+			t.Errorf("сообщение кода %s содержит шаблон форматирования", code)
+		}
+		if r, _ := utf8.DecodeRuneInString(msgs[0]); unicode.IsUpper(r) {
+			t.Errorf("русское сообщение кода %s начинается с заглавной буквы", code)
+		}
+	}
+	if got := CodeHTTPStatus.Message(Lang("de")); got != catalog[CodeHTTPStatus][0] {
+		t.Errorf("для неизвестного языка ожидался русский, получено %q", got)
+	}
+}
@@ -0,0 +1,167 @@
+// Пакет mirror синхронизирует объекты Андромеды с локальной базой SQLite для аналитических запросов.
+package mirror
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type (
+	//Описание таблицы, колонки которой совпадают с полями структуры ответа
+	table struct {
+		name    string
+		typ     reflect.Type
+		siteCol bool     //Добавить колонку SiteId со ссылкой на объект
+		key     []string //Первичный ключ
+		columns []string
+		fields  []int
+	}
+)
+
+var (
+	tableSites     = newTable("sites", andromeda.GetSitesResponse{}, false, "Id")
+	tableCustomers = newTable("customers", andromeda.GetCustomerResponse{}, true, "SiteId", "Id")
+	tableParts     = newTable("parts", andromeda.GetPartsResponse{}, true, "SiteId", "Id")
+	tableZones     = newTable("zones", andromeda.GetZonesResponse{}, true, "SiteId", "Id")
+	tableMyAlarm   = newTable("myalarm_users", andromeda.UserMyAlarmResponse{}, true, "SiteId", "CustomerID")
+
+	tables = []*table{tableSites, tableCustomers, tableParts, tableZones, tableMyAlarm}
+)
+
+// Служебные таблицы
+const serviceSchema = `
+CREATE TABLE IF NOT EXISTS sync_runs (
+	Id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	StartedAt   TEXT NOT NULL,
+	FinishedAt  TEXT,
+	SitesTotal  INTEGER NOT NULL DEFAULT 0,
+	SitesSynced INTEGER NOT NULL DEFAULT 0,
+	SitesSkipped INTEGER NOT NULL DEFAULT 0,
+	SitesFailed INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS sync_errors (
+	RunId INTEGER NOT NULL REFERENCES sync_runs(Id),
+	Site  TEXT NOT NULL,
+	Error TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS site_sync (
+	SiteId    TEXT PRIMARY KEY,
+	Query     TEXT NOT NULL,
+	RunId     INTEGER NOT NULL REFERENCES sync_runs(Id),
+	SyncedAt  TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS site_sync_query ON site_sync(Query);
+`
+
+func newTable(name string, v any, siteCol bool, key ...string) *table {
+	t := &table{name: name, typ: reflect.TypeOf(v), siteCol: siteCol, key: key}
+	for idx := 0; idx < t.typ.NumField(); idx++ {
+		f := t.typ.Field(idx)
+		if !f.IsExported() || sqlType(f.Type) == "" {
+			continue
+		}
+		col := strings.Split(f.Tag.Get("json"), ",")[0]
+		if col == "-" {
+			continue
+		}
+		if col == "" {
+			col = f.Name
+		}
+		t.columns = append(t.columns, col)
+		t.fields = append(t.fields, idx)
+	}
+
+	return t
+}
+
+// Тип колонки SQLite для типа поля структуры. Денежные поля (andromeda.Money) хранятся целым числом копеек
+func sqlType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "TEXT"
+	case reflect.Bool, reflect.Int, reflect.Int64:
+		return "INTEGER"
+	case reflect.Float64:
+		return "REAL"
+	}
+
+	return ""
+}
+
+func (t *table) ddl() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TABLE IF NOT EXISTS %s (\n", t.name)
+	if t.siteCol {
+		b.WriteString("\tSiteId TEXT NOT NULL REFERENCES sites(Id) ON DELETE CASCADE,\n")
+	}
+	for idx, col := range t.columns {
+		fmt.Fprintf(&b, "\t%s %s,\n", col, sqlType(t.typ.Field(t.fields[idx]).Type))
+	}
+	fmt.Fprintf(&b, "\tPRIMARY KEY (%s)\n);\n", strings.Join(t.key, ", "))
+	if t.siteCol {
+		fmt.Fprintf(&b, "CREATE INDEX IF NOT EXISTS %s_site ON %s(SiteId);\n", t.name, t.name)
+	}
+
+	return b.String()
+}
+
+// Запрос вставки или замены строки
+func (t *table) upsert() string {
+	cols := t.columns
+	if t.siteCol {
+		cols = append([]string{"SiteId"}, cols...)
+	}
+
+	return fmt.Sprintf("INSERT OR REPLACE INTO %s (%s) VALUES (%s)",
+		t.name, strings.Join(cols, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(cols)), ", "))
+}
+
+// Значения колонок для записи rec
+func (t *table) values(siteId string, rec any) []any {
+	v := reflect.ValueOf(rec)
+	args := make([]any, 0, len(t.fields)+1)
+	if t.siteCol {
+		args = append(args, siteId)
+	}
+	for _, idx := range t.fields {
+		args = append(args, v.Field(idx).Interface())
+	}
+
+	return args
+}
+
+// Открытие (создание) базы SQLite и создание схемы
+func Open(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", path+"?_foreign_keys=on&_busy_timeout=5000")
+	if err != nil {
+		return nil, &andromeda.Error{Code: andromeda.CodeDBOpen, Detail: path, Err: err}
+	}
+	db.SetMaxOpenConns(1)
+
+	if err := Migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Создание таблиц, если их ещё нет
+func Migrate(db *sql.DB) error {
+	var b strings.Builder
+	for _, t := range tables {
+		b.WriteString(t.ddl())
+	}
+	b.WriteString(serviceSchema)
+
+	if _, err := db.Exec(b.String()); err != nil {
+		return &andromeda.Error{Code: andromeda.CodeDBSchema, Err: err}
+	}
+
+	return nil
+}
@@ -0,0 +1,262 @@
+package mirror
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"sync"
+	"time"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+// Время на запись итогов синхронизации после отмены
+const finishTimeout = 10 * time.Second
+
+type (
+	//Методы SDK, которые использует Syncer. Реализуется *andromeda.Client
+	API = andromeda.SiteDataAPI
+
+	//Синхронизация объектов в локальную базу
+	Syncer struct {
+		Client        API
+		Config        andromeda.Config
+		DB            *sql.DB
+		UserName      string        //Имя пользователя, от которого делаются запросы (необязательное поле)
+		Concurrency   int           //Количество объектов, загружаемых одновременно
+		RatePerSecond float64       //Ограничение частоты запросов к серверу, 0 - без ограничения
+		MaxAge        time.Duration //Инкрементальная синхронизация: пропускать объекты, синхронизированные не раньше MaxAge назад
+		Prune         bool          //Удалять из базы объекты, не входящие в список синхронизации (удалённые на сервере)
+	}
+
+	//Результат синхронизации
+	Run struct {
+		Id         int64
+		StartedAt  time.Time
+		FinishedAt time.Time
+		Total      int
+		Synced     int
+		Skipped    int
+		Failed     int
+		Removed    int              //Объектов, удалённых из базы при Prune
+		Errors     map[string]error //Ошибки по номеру или идентификатору объекта
+	}
+)
+
+// Синхронизация объектов, заданных номерами или идентификаторами.
+// Данные каждого объекта (карточка, ответственные, разделы, шлейфы, пользователи MyAlarm) заменяются целиком в одной транзакции.
+// При Prune список sites считается полным: объекты базы, которых в нём нет, удаляются вместе с их данными.
+func (s *Syncer) Sync(ctx context.Context, sites []string) (Run, error) {
+	run := Run{StartedAt: time.Now(), Total: len(sites), Errors: map[string]error{}}
+
+	res, err := s.DB.ExecContext(ctx, "INSERT INTO sync_runs (StartedAt, SitesTotal) VALUES (?, ?)",
+		stamp(run.StartedAt), run.Total)
+	if err != nil {
+		return run, &andromeda.Error{Code: andromeda.CodeDBWrite, Detail: "sync_runs", Err: err}
+	}
+	if run.Id, err = res.LastInsertId(); err != nil {
+		return run, &andromeda.Error{Code: andromeda.CodeDBWrite, Detail: "sync_runs", Err: err}
+	}
+
+	pending := sites
+	if s.MaxAge > 0 {
+		if pending, err = s.stale(ctx, sites); err != nil {
+			return run, err
+		}
+	}
+	run.Skipped = len(sites) - len(pending)
+
+	var mu sync.Mutex
+	results, _ := andromeda.Bulk(ctx, pending, func(ctx context.Context, query string) (struct{}, error) {
+		data, err := s.fetch(ctx, query)
+		if err != nil {
+			return struct{}{}, err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		return struct{}{}, s.store(ctx, run.Id, query, data)
+	}, andromeda.BulkOptions{Concurrency: s.Concurrency, RatePerSecond: s.RatePerSecond})
+
+	for _, r := range results {
+		if r.Err != nil {
+			run.Failed++
+			run.Errors[r.Input] = r.Err
+			continue
+		}
+		run.Synced++
+	}
+
+	if s.Prune && ctx.Err() == nil {
+		if run.Removed, err = s.prune(ctx, sites); err != nil {
+			return run, err
+		}
+	}
+
+	run.FinishedAt = time.Now()
+	if err := s.finish(ctx, run); err != nil {
+		return run, err
+	}
+
+	return run, nil
+}
+
+// Объекты, которые не синхронизировались в течение MaxAge
+func (s *Syncer) stale(ctx context.Context, sites []string) ([]string, error) {
+	since := stamp(time.Now().Add(-s.MaxAge))
+	pending := make([]string, 0, len(sites))
+	for _, query := range sites {
+		var n int
+		err := s.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM site_sync WHERE (Query = ? OR SiteId = ?) AND SyncedAt >= ?",
+			query, query, since).Scan(&n)
+		if err != nil {
+			return nil, &andromeda.Error{Code: andromeda.CodeDBRead, Detail: "site_sync", Err: err}
+		}
+		if n == 0 {
+			pending = append(pending, query)
+		}
+	}
+
+	return pending, nil
+}
+
+// Удаление объектов, которые не заданы в sites ни идентификатором, ни номером, ни запросом последней синхронизации.
+// Ответственные, разделы, шлейфы и пользователи MyAlarm удаляются каскадно
+func (s *Syncer) prune(ctx context.Context, sites []string) (removed int, err error) {
+	listed := make(map[string]bool, len(sites))
+	for _, query := range sites {
+		listed[query] = true
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, &andromeda.Error{Code: andromeda.CodeDBWrite, Detail: "sites", Err: err}
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	rows, err := tx.QueryContext(ctx, "SELECT s.Id, s.AccountNumber, COALESCE(ss.Query, '') FROM sites s LEFT JOIN site_sync ss ON ss.SiteId = s.Id")
+	if err != nil {
+		return 0, &andromeda.Error{Code: andromeda.CodeDBRead, Detail: "sites", Err: err}
+	}
+	var missing []string
+	for rows.Next() {
+		var (
+			id, query string
+			number    int
+		)
+		if err = rows.Scan(&id, &number, &query); err != nil {
+			rows.Close()
+			return 0, &andromeda.Error{Code: andromeda.CodeDBRead, Detail: "sites", Err: err}
+		}
+		if !listed[id] && !listed[query] && !listed[strconv.Itoa(number)] {
+			missing = append(missing, id)
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, &andromeda.Error{Code: andromeda.CodeDBRead, Detail: "sites", Err: err}
+	}
+
+	for _, id := range missing {
+		if _, err = tx.ExecContext(ctx, "DELETE FROM sites WHERE Id = ?", id); err != nil {
+			return 0, &andromeda.Error{Code: andromeda.CodeDBWrite, Detail: id, Err: err}
+		}
+		if _, err = tx.ExecContext(ctx, "DELETE FROM site_sync WHERE SiteId = ?", id); err != nil {
+			return 0, &andromeda.Error{Code: andromeda.CodeDBWrite, Detail: id, Err: err}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, &andromeda.Error{Code: andromeda.CodeDBWrite, Detail: "sites", Err: err}
+	}
+	return len(missing), nil
+}
+
+// Загрузка всех данных объекта через SDK
+func (s *Syncer) fetch(ctx context.Context, query string) (andromeda.SiteData, error) {
+	return andromeda.FetchSiteData(ctx, s.Client, andromeda.GetSitesInput{Id: query, UserName: s.UserName, Config: s.Config})
+}
+
+// Запись данных объекта в базу
+func (s *Syncer) store(ctx context.Context, runId int64, query string, data andromeda.SiteData) (err error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return &andromeda.Error{Code: andromeda.CodeDBWrite, Detail: data.Site.Id, Err: err}
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			err = &andromeda.Error{Code: andromeda.CodeDBWrite, Detail: data.Site.Id, Err: err}
+		}
+	}()
+
+	siteId := data.Site.Id
+	if _, err = tx.ExecContext(ctx, tableSites.upsert(), tableSites.values("", data.Site)...); err != nil {
+		return err
+	}
+
+	for _, t := range []*table{tableCustomers, tableParts, tableZones, tableMyAlarm} {
+		if _, err = tx.ExecContext(ctx, "DELETE FROM "+t.name+" WHERE SiteId = ?", siteId); err != nil {
+			return err
+		}
+	}
+	for _, rec := range data.Customers {
+		if _, err = tx.ExecContext(ctx, tableCustomers.upsert(), tableCustomers.values(siteId, rec)...); err != nil {
+			return err
+		}
+	}
+	for _, rec := range data.Parts {
+		if _, err = tx.ExecContext(ctx, tableParts.upsert(), tableParts.values(siteId, rec)...); err != nil {
+			return err
+		}
+	}
+	for _, rec := range data.Zones {
+		if _, err = tx.ExecContext(ctx, tableZones.upsert(), tableZones.values(siteId, rec)...); err != nil {
+			return err
+		}
+	}
+	for _, rec := range data.Users {
+		if _, err = tx.ExecContext(ctx, tableMyAlarm.upsert(), tableMyAlarm.values(siteId, rec)...); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT OR REPLACE INTO site_sync (SiteId, Query, RunId, SyncedAt) VALUES (?, ?, ?, ?)",
+		siteId, query, runId, stamp(time.Now()))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Запись итогов синхронизации. Выполняется и после отмены ctx, чтобы прерванная синхронизация не осталась
+// незавершённой в sync_runs
+func (s *Syncer) finish(ctx context.Context, run Run) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finishTimeout)
+	defer cancel()
+
+	_, err := s.DB.ExecContext(ctx, "UPDATE sync_runs SET FinishedAt = ?, SitesSynced = ?, SitesSkipped = ?, SitesFailed = ? WHERE Id = ?",
+		stamp(run.FinishedAt), run.Synced, run.Skipped, run.Failed, run.Id)
+	if err != nil {
+		return &andromeda.Error{Code: andromeda.CodeDBWrite, Detail: "sync_runs", Err: err}
+	}
+
+	for site, siteErr := range run.Errors {
+		_, err := s.DB.ExecContext(ctx, "INSERT INTO sync_errors (RunId, Site, Error) VALUES (?, ?, ?)", run.Id, site, siteErr.Error())
+		if err != nil {
+			return &andromeda.Error{Code: andromeda.CodeDBWrite, Detail: "sync_errors", Err: err}
+		}
+	}
+
+	return nil
+}
+
+// Время в формате, хранимом в базе (UTC, сравнимо как строка)
+func stamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
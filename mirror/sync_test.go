@@ -0,0 +1,260 @@
+package mirror
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+// Андромеда в памяти: ответственные по номеру объекта, объект запрашивается номером или идентификатором; onGetSites вызывается перед каждым запросом карточки
+type fakeAPI struct {
+	mu         sync.Mutex
+	customers  map[string][]string
+	failSites  map[string]bool //Номера объектов, карточку которых не удаётся получить
+	failLists  map[string]bool //Номера объектов, списки которых не удаётся получить
+	onGetSites func()
+}
+
+func newFakeAPI() *fakeAPI {
+	return &fakeAPI{
+		customers: map[string][]string{"1": {"c1", "c2"}, "2": {"c3"}, "3": {}},
+		failSites: map[string]bool{},
+		failLists: map[string]bool{},
+	}
+}
+
+func (f *fakeAPI) GetSites(ctx context.Context, in andromeda.GetSitesInput) (andromeda.GetSitesResponse, error) {
+	if f.onGetSites != nil {
+		f.onGetSites()
+	}
+	if err := ctx.Err(); err != nil {
+		return andromeda.GetSitesResponse{}, err
+	}
+	number := strings.TrimPrefix(in.Id, "guid-")
+	if f.failSites[number] {
+		return andromeda.GetSitesResponse{}, errors.New("объект не найден")
+	}
+	return andromeda.GetSitesResponse{Id: "guid-" + number, Name: "Объект " + number}, nil
+}
+
+func (f *fakeAPI) Customers(ctx context.Context, in andromeda.GetCustomersInput) ([]andromeda.GetCustomerResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	number := in.SiteId[len("guid-"):]
+	if f.failLists[number] {
+		return nil, errors.New("нет доступа")
+	}
+	var list []andromeda.GetCustomerResponse
+	for _, id := range f.customers[number] {
+		list = append(list, andromeda.GetCustomerResponse{Id: id})
+	}
+	return list, nil
+}
+
+func (f *fakeAPI) GetParts(ctx context.Context, in andromeda.GetPartsInput) ([]andromeda.GetPartsResponse, error) {
+	return []andromeda.GetPartsResponse{{Id: "p-" + in.SiteId, PartNumber: 1}}, nil
+}
+
+func (f *fakeAPI) GetZones(ctx context.Context, in andromeda.GetZonesInput) ([]andromeda.GetZonesResponse, error) {
+	return []andromeda.GetZonesResponse{{Id: "z-" + in.SiteId, ZoneNumber: 1}}, nil
+}
+
+func (f *fakeAPI) GetUsersMyAlarm(ctx context.Context, in andromeda.GetUsersMyAlarmInput) ([]andromeda.UserMyAlarmResponse, error) {
+	return nil, nil
+}
+
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := Open(filepath.Join(t.TempDir(), "mirror.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func count(t *testing.T, db *sql.DB, query string, args ...any) int {
+	t.Helper()
+
+	var n int
+	if err := db.QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestSync(t *testing.T) {
+	tests := []struct {
+		name      string
+		sites     []string
+		failSites []string
+		failLists []string
+		synced    int
+		failed    int
+		customers int
+	}{
+		{name: "все объекты", sites: []string{"1", "2", "3"}, synced: 3, customers: 3},
+		{name: "карточка недоступна", sites: []string{"1", "2"}, failSites: []string{"2"}, synced: 1, failed: 1, customers: 2},
+		{name: "список недоступен", sites: []string{"1", "2"}, failLists: []string{"1"}, synced: 1, failed: 1, customers: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI()
+			for _, id := range tt.failSites {
+				api.failSites[id] = true
+			}
+			for _, id := range tt.failLists {
+				api.failLists[id] = true
+			}
+			db := newTestDB(t)
+
+			run, err := (&Syncer{Client: api, DB: db}).Sync(context.Background(), tt.sites)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if run.Synced != tt.synced || run.Failed != tt.failed || len(run.Errors) != tt.failed {
+				t.Fatalf("синхронизировано %d, ошибок %d (%v), ожидалось %d и %d", run.Synced, run.Failed, run.Errors, tt.synced, tt.failed)
+			}
+			if got := count(t, db, "SELECT COUNT(*) FROM customers"); got != tt.customers {
+				t.Errorf("ответственных %d, ожидалось %d", got, tt.customers)
+			}
+			if got := count(t, db, "SELECT COUNT(*) FROM sync_errors WHERE RunId = ?", run.Id); got != tt.failed {
+				t.Errorf("записей об ошибках %d, ожидалось %d", got, tt.failed)
+			}
+			if got := count(t, db, "SELECT COUNT(*) FROM sync_runs WHERE Id = ? AND FinishedAt IS NOT NULL AND SitesSynced = ?", run.Id, tt.synced); got != 1 {
+				t.Errorf("итоги синхронизации не записаны")
+			}
+		})
+	}
+}
+
+// Повторная синхронизация заменяет данные объекта целиком
+func TestSyncReplacesSiteData(t *testing.T) {
+	api := newFakeAPI()
+	db := newTestDB(t)
+	s := &Syncer{Client: api, DB: db}
+
+	if _, err := s.Sync(context.Background(), []string{"1"}); err != nil {
+		t.Fatal(err)
+	}
+	api.customers["1"] = []string{"c9"}
+	if _, err := s.Sync(context.Background(), []string{"1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := count(t, db, "SELECT COUNT(*) FROM customers WHERE SiteId = 'guid-1'"); got != 1 {
+		t.Fatalf("ответственных %d, ожидался 1", got)
+	}
+	if got := count(t, db, "SELECT COUNT(*) FROM customers WHERE Id = 'c9'"); got != 1 {
+		t.Fatal("новый ответственный не записан")
+	}
+}
+
+func TestSyncPrune(t *testing.T) {
+	tests := []struct {
+		name    string
+		prune   bool
+		second  []string //Список второй синхронизации
+		removed int
+		sites   []string //Объекты в базе после второй синхронизации
+	}{
+		{name: "объект удалён на сервере", prune: true, second: []string{"1", "3"}, removed: 1, sites: []string{"guid-1", "guid-3"}},
+		{name: "объект задан идентификатором", prune: true, second: []string{"guid-1", "2", "3"}, sites: []string{"guid-1", "guid-2", "guid-3"}},
+		{name: "без Prune объекты не удаляются", second: []string{"1"}, sites: []string{"guid-1", "guid-2", "guid-3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			s := &Syncer{Client: newFakeAPI(), DB: db, Prune: tt.prune}
+
+			if _, err := s.Sync(context.Background(), []string{"1", "2", "3"}); err != nil {
+				t.Fatal(err)
+			}
+			run, err := s.Sync(context.Background(), tt.second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if run.Removed != tt.removed {
+				t.Fatalf("удалено %d, ожидалось %d", run.Removed, tt.removed)
+			}
+
+			var sites []string
+			rows, err := db.Query("SELECT Id FROM sites ORDER BY Id")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var id string
+				if err := rows.Scan(&id); err != nil {
+					t.Fatal(err)
+				}
+				sites = append(sites, id)
+			}
+			if !slices.Equal(sites, tt.sites) {
+				t.Fatalf("объекты %v, ожидалось %v", sites, tt.sites)
+			}
+
+			// Данные удалённых объектов удаляются вместе с карточкой
+			if got := count(t, db, "SELECT COUNT(*) FROM parts WHERE SiteId NOT IN (SELECT Id FROM sites)"); got != 0 {
+				t.Errorf("разделов удалённых объектов: %d", got)
+			}
+			if got := count(t, db, "SELECT COUNT(*) FROM site_sync WHERE SiteId NOT IN (SELECT Id FROM sites)"); got != 0 {
+				t.Errorf("записей о синхронизации удалённых объектов: %d", got)
+			}
+		})
+	}
+}
+
+func TestSyncMaxAge(t *testing.T) {
+	db := newTestDB(t)
+	s := &Syncer{Client: newFakeAPI(), DB: db, MaxAge: time.Hour}
+
+	if _, err := s.Sync(context.Background(), []string{"1"}); err != nil {
+		t.Fatal(err)
+	}
+	run, err := s.Sync(context.Background(), []string{"1", "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Skipped != 1 || run.Synced != 1 {
+		t.Fatalf("пропущено %d, синхронизировано %d, ожидалось 1 и 1", run.Skipped, run.Synced)
+	}
+}
+
+// Прерванная синхронизация всё равно отмечается завершённой
+func TestSyncCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	api := newFakeAPI()
+	api.onGetSites = cancel
+	db := newTestDB(t)
+
+	run, err := (&Syncer{Client: api, DB: db, Concurrency: 1}).Sync(ctx, []string{"1", "2", "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Synced != 0 || run.Failed != 3 {
+		t.Fatalf("синхронизировано %d, ошибок %d, ожидалось 0 и 3", run.Synced, run.Failed)
+	}
+	if got := count(t, db, "SELECT COUNT(*) FROM sync_runs WHERE Id = ? AND FinishedAt IS NOT NULL AND SitesFailed = 3", run.Id); got != 1 {
+		t.Fatal("прерванная синхронизация не отмечена завершённой")
+	}
+	if got := count(t, db, "SELECT COUNT(*) FROM sync_errors WHERE RunId = ?", run.Id); got != 3 {
+		t.Fatalf("записей об ошибках %d, ожидалось 3", got)
+	}
+}
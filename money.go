@@ -0,0 +1,159 @@
+package andromeda
+
+import (
+	"bytes"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Денежная сумма с точностью до копейки. Хранится целым числом копеек, поэтому
+// значения ContractPrice и MoneyBalance читаются из JSON и записываются обратно без потерь.
+// Используется в ответах и во входных структурах методов, изменяющих карточку объекта.
+type Money int64
+
+// Сумма из копеек
+func MoneyFromKopecks(kopecks int64) Money {
+	return Money(kopecks)
+}
+
+// Сумма из рублей и копеек, например MoneyFromRubles(-12, 50) = -12,50 ₽
+func MoneyFromRubles(rubles, kopecks int64) Money {
+	if rubles < 0 {
+		return Money(rubles*100 - kopecks)
+	}
+	return Money(rubles*100 + kopecks)
+}
+
+// Разбор суммы в рублях из строки: «1234.56», «-0,5», «1 234,56», «1e3», «12.3400».
+// Для суммы с долями копейки («1.234») возвращается ошибка CodeMoneyFormat
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "₽"))
+	s = strings.NewReplacer(" ", "", " ", "", ",", ".").Replace(s)
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, &Error{Code: CodeMoneyFormat, Detail: strconv.Quote(s)}
+	}
+
+	// Сумма с долями копейки не округляется: такое значение не может быть записано обратно без потерь
+	r.Mul(r, big.NewRat(100, 1))
+	if !r.IsInt() || !r.Num().IsInt64() {
+		return 0, &Error{Code: CodeMoneyFormat, Detail: strconv.Quote(s)}
+	}
+
+	return Money(r.Num().Int64()), nil
+}
+
+// Количество копеек
+func (m Money) Kopecks() int64 {
+	return int64(m)
+}
+
+// Приближённое значение в рублях, только для вывода и вычислений, не требующих точности
+func (m Money) Float64() float64 {
+	return float64(m) / 100
+}
+
+// Сумма двух значений
+func (m Money) Add(o Money) Money {
+	return m + o
+}
+
+// Разность двух значений
+func (m Money) Sub(o Money) Money {
+	return m - o
+}
+
+// Умножение на целое число (например, платёж за n месяцев)
+func (m Money) Mul(n int64) Money {
+	return m * Money(n)
+}
+
+// Противоположное значение
+func (m Money) Neg() Money {
+	return -m
+}
+
+// Абсолютное значение
+func (m Money) Abs() Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+// Признак отрицательной суммы
+func (m Money) IsNegative() bool {
+	return m < 0
+}
+
+// Сумма в виде десятичного числа с точкой, например «-1234.50»
+func (m Money) Decimal() string {
+	return m.format(".", "")
+}
+
+// Сумма в рублях с разделителем разрядов, например «-1 234,50 ₽»
+func (m Money) String() string {
+	return m.format(",", " ") + " ₽"
+}
+
+func (m Money) format(point, group string) string {
+	sign := ""
+	v := uint64(m)
+	if m < 0 {
+		sign = "-"
+		v = uint64(-m)
+	}
+
+	rub := strconv.FormatUint(v/100, 10)
+	if group != "" {
+		var b strings.Builder
+		for idx, r := range rub {
+			if idx > 0 && (len(rub)-idx)%3 == 0 {
+				b.WriteString(group)
+			}
+			b.WriteRune(r)
+		}
+		rub = b.String()
+	}
+
+	kop := strconv.FormatUint(v%100, 10)
+	if len(kop) < 2 {
+		kop = "0" + kop
+	}
+
+	return sign + rub + point + kop
+}
+
+// Запись в JSON числом в рублях
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.Decimal()), nil
+}
+
+// Чтение из JSON: число в рублях, строка с числом или null
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) > 1 && data[0] == '"' {
+		s, err := strconv.Unquote(string(data))
+		if err != nil {
+			return &Error{Code: CodeMoneyFormat, Err: err}
+		}
+		if s == "" {
+			*m = 0
+			return nil
+		}
+		data = []byte(s)
+	}
+
+	v, err := ParseMoney(string(data))
+	if err != nil {
+		return err
+	}
+	*m = v
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package andromeda
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Money
+		err  bool
+	}{
+		{in: "1234.56", want: 123456},
+		{in: "-0,5", want: -50},
+		{in: "1 234,56 ₽", want: 123456},
+		{in: "1 234,56", want: 123456},
+		{in: "1e3", want: 100000},
+		{in: "12.3400", want: 1234},
+		{in: "0", want: 0},
+		{in: "1.234", err: true},
+		{in: "-0.005", err: true},
+		{in: "1e-3", err: true},
+		{in: "abc", err: true},
+		{in: "", err: true},
+		{in: "1e30", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseMoney(tt.in)
+			if tt.err {
+				if CodeOf(err) != CodeMoneyFormat {
+					t.Fatalf("ошибка %v, ожидался код %q (значение %d)", err, CodeMoneyFormat, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("%d, ожидалось %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Money
+		out  string
+		err  bool
+	}{
+		{in: `1234.5`, want: 123450, out: `1234.50`},
+		{in: `"-12,05"`, want: -1205, out: `-12.05`},
+		{in: `""`, want: 0, out: `0.00`},
+		{in: `null`, want: 0, out: `0.00`},
+		{in: `0.001`, err: true},
+		{in: `"abc"`, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var m Money
+			err := json.Unmarshal([]byte(tt.in), &m)
+			if tt.err {
+				if CodeOf(err) != CodeMoneyFormat {
+					t.Fatalf("ошибка %v, ожидался код %q", err, CodeMoneyFormat)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m != tt.want {
+				t.Fatalf("%d, ожидалось %d", m, tt.want)
+			}
+			out, _ := json.Marshal(m)
+			if string(out) != tt.out {
+				t.Fatalf("запись %s, ожидалось %s", out, tt.out)
+			}
+		})
+	}
+}
+
+func TestMoneyFormat(t *testing.T) {
+	tests := []struct {
+		m       Money
+		decimal string
+		str     string
+	}{
+		{m: MoneyFromRubles(-12, 50), decimal: "-12.50", str: "-12,50 ₽"},
+		{m: MoneyFromRubles(1234567, 5), decimal: "1234567.05", str: "1 234 567,05 ₽"},
+		{m: MoneyFromKopecks(7), decimal: "0.07", str: "0,07 ₽"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.decimal, func(t *testing.T) {
+			if got := tt.m.Decimal(); got != tt.decimal {
+				t.Errorf("Decimal %q, ожидалось %q", got, tt.decimal)
+			}
+			if got := tt.m.String(); got != tt.str {
+				t.Errorf("String %q, ожидалось %q", got, tt.str)
+			}
+		})
+	}
+}
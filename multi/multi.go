@@ -0,0 +1,261 @@
+package multi
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+type (
+	//Клиент для нескольких серверов: запросы по объекту направляются на сервер объекта,
+	//поисковые запросы выполняются на всех серверах
+	MultiClient struct {
+		Registry    *Registry
+		Concurrency int //Количество серверов, опрашиваемых одновременно (по умолчанию все)
+	}
+
+	//Значение с именем сервера, с которого оно получено
+	Tagged[T any] struct {
+		Server string `json:"server"`
+		Value  T      `json:"value"`
+	}
+
+	//Ошибка запроса к одному серверу
+	ServerError struct {
+		Server string
+		Err    error
+	}
+
+	//Ошибки запросов к нескольким серверам в порядке регистрации серверов
+	Errors []*ServerError
+)
+
+func (e *ServerError) Error() string {
+	return e.Server + ": " + e.Err.Error()
+}
+
+func (e *ServerError) Unwrap() error {
+	return e.Err
+}
+
+// Ошибка сервера на языке lang
+func (e *ServerError) Localize(lang andromeda.Lang) error {
+	return &ServerError{Server: e.Server, Err: andromeda.Localize(e.Err, lang)}
+}
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for idx, err := range e {
+		msgs[idx] = err.Error()
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+func (e Errors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for idx, err := range e {
+		errs[idx] = err
+	}
+
+	return errs
+}
+
+// Ошибки серверов на языке lang
+func (e Errors) Localize(lang andromeda.Lang) error {
+	errs := make(Errors, len(e))
+	for idx, err := range e {
+		errs[idx] = err.Localize(lang).(*ServerError)
+	}
+
+	return errs
+}
+
+// Выполнение fn на всех серверах реестра. Результаты успешных серверов возвращаются в порядке регистрации
+// вместе с Errors, если хотя бы один сервер вернул ошибку
+func FanOut[T any](ctx context.Context, r *Registry, concurrency int, fn func(context.Context, Target) (T, error)) ([]Tagged[T], error) {
+	targets := r.Targets()
+	if concurrency <= 0 {
+		concurrency = len(targets)
+	}
+
+	results, _ := andromeda.Bulk(ctx, targets, fn, andromeda.BulkOptions{Concurrency: concurrency})
+
+	var (
+		out  []Tagged[T]
+		errs Errors
+	)
+	for _, res := range results {
+		if res.Err != nil {
+			errs = append(errs, &ServerError{Server: res.Input.Name, Err: res.Err})
+			continue
+		}
+		out = append(out, Tagged[T]{Server: res.Input.Name, Value: res.Output})
+	}
+	if len(errs) > 0 {
+		return out, errs
+	}
+
+	return out, nil
+}
+
+// Выполнение списочного метода на всех серверах с объединением списков
+func FanOutList[T any](ctx context.Context, r *Registry, concurrency int, fn func(context.Context, Target) ([]T, error)) ([]Tagged[T], error) {
+	lists, err := FanOut(ctx, r, concurrency, fn)
+
+	var out []Tagged[T]
+	for _, list := range lists {
+		for _, v := range list.Value {
+			out = append(out, Tagged[T]{Server: list.Server, Value: v})
+		}
+	}
+
+	return out, err
+}
+
+// Сервер объекта
+func (m *MultiClient) Site(siteId string) (Target, error) {
+	return m.Registry.ForSite(siteId)
+}
+
+// Сервер арендатора
+func (m *MultiClient) Tenant(tenant string) (Target, error) {
+	return m.Registry.ForTenant(tenant)
+}
+
+// Поиск объекта по номеру или идентификатору. Если объект уже привязан к серверу, запрос выполняется
+// только на нём, иначе на всех серверах. Объект привязывается к серверу, только если он найден на одном сервере,
+// а остальные ответили, что объекта нет (ошибка CodeServerError). Если объект найден на нескольких серверах,
+// возвращается ошибка с кодом CodeSiteAmbiguous и списком серверов; если часть серверов недоступна,
+// найденный объект возвращается вместе с Errors этих серверов
+func (m *MultiClient) GetSites(ctx context.Context, input andromeda.GetSitesInput) (Tagged[andromeda.GetSitesResponse], error) {
+	if t, err := m.Registry.ForSite(input.Id); err == nil {
+		site, err := t.GetSites(ctx, input)
+		if err != nil {
+			return Tagged[andromeda.GetSitesResponse]{}, &ServerError{Server: t.Name, Err: err}
+		}
+		return Tagged[andromeda.GetSitesResponse]{Server: t.Name, Value: site}, nil
+	}
+
+	found, err := FanOut(ctx, m.Registry, m.Concurrency, func(ctx context.Context, t Target) (andromeda.GetSitesResponse, error) {
+		return t.GetSites(ctx, input)
+	})
+	if len(found) == 0 {
+		return Tagged[andromeda.GetSitesResponse]{}, err
+	}
+	if len(found) > 1 {
+		servers := make([]string, len(found))
+		for idx, site := range found {
+			servers[idx] = site.Server
+		}
+		return Tagged[andromeda.GetSitesResponse]{}, &andromeda.Error{Code: andromeda.CodeSiteAmbiguous, Detail: input.Id + " (" + strings.Join(servers, ", ") + ")"}
+	}
+
+	site := found[0]
+	if failed := unavailable(err); len(failed) > 0 {
+		return site, failed
+	}
+	m.Registry.MapSite(input.Id, site.Server)
+	if site.Value.Id != "" {
+		m.Registry.MapSite(site.Value.Id, site.Server)
+	}
+
+	return site, nil
+}
+
+// Запрос метода GetCustomers на сервере объекта
+func (m *MultiClient) Customers(ctx context.Context, input andromeda.GetCustomersInput) ([]andromeda.GetCustomerResponse, error) {
+	t, err := m.site(ctx, input.SiteId, input.UserName)
+	if err != nil {
+		return nil, err
+	}
+	return t.Customers(ctx, input)
+}
+
+// Запрос метода PostCheckPanic на сервере объекта
+func (m *MultiClient) PostCheckPanic(ctx context.Context, input andromeda.PostCheckPanicInput) (andromeda.PostCheckPanicResponse, error) {
+	t, err := m.site(ctx, input.SiteId, input.UserName)
+	if err != nil {
+		return andromeda.PostCheckPanicResponse{}, err
+	}
+	return t.PostCheckPanic(ctx, input)
+}
+
+// Запрос метода GetUsersMyAlarm на сервере объекта
+func (m *MultiClient) GetUsersMyAlarm(ctx context.Context, input andromeda.GetUsersMyAlarmInput) ([]andromeda.UserMyAlarmResponse, error) {
+	t, err := m.site(ctx, input.SiteId, input.UserName)
+	if err != nil {
+		return nil, err
+	}
+	return t.GetUsersMyAlarm(ctx, input)
+}
+
+// Запрос метода GetParts на сервере объекта
+func (m *MultiClient) GetParts(ctx context.Context, input andromeda.GetPartsInput) ([]andromeda.GetPartsResponse, error) {
+	t, err := m.site(ctx, input.SiteId, input.UserName)
+	if err != nil {
+		return nil, err
+	}
+	return t.GetParts(ctx, input)
+}
+
+// Запрос метода GetZones на сервере объекта
+func (m *MultiClient) GetZones(ctx context.Context, input andromeda.GetZonesInput) ([]andromeda.GetZonesResponse, error) {
+	t, err := m.site(ctx, input.SiteId, input.UserName)
+	if err != nil {
+		return nil, err
+	}
+	return t.GetZones(ctx, input)
+}
+
+// Объекты пользователя MyAlarm по телефону со всех серверов
+func (m *MultiClient) GetUserObjectMyAlarm(ctx context.Context, input andromeda.GetUserObjectMyAlarmInput) ([]Tagged[andromeda.GetUserObjectMyAlarmResponse], error) {
+	return FanOutList(ctx, m.Registry, m.Concurrency, func(ctx context.Context, t Target) ([]andromeda.GetUserObjectMyAlarmResponse, error) {
+		return t.GetUserObjectMyAlarm(ctx, input)
+	})
+}
+
+// Профиль пользователя MyAlarm по телефону со всех серверов
+func (m *MultiClient) GetPhoneProfile(ctx context.Context, input andromeda.GetPhoneProfileInput) ([]Tagged[andromeda.PhoneProfileEntry], error) {
+	return FanOutList(ctx, m.Registry, m.Concurrency, func(ctx context.Context, t Target) ([]andromeda.PhoneProfileEntry, error) {
+		return t.GetPhoneProfile(ctx, input)
+	})
+}
+
+// Ошибки серверов, кроме ответов об отсутствии объекта
+func unavailable(err error) Errors {
+	var errs Errors
+	if !errors.As(err, &errs) {
+		return nil
+	}
+
+	var failed Errors
+	for _, e := range errs {
+		if andromeda.CodeOf(e) != andromeda.CodeServerError {
+			failed = append(failed, e)
+		}
+	}
+
+	return failed
+}
+
+// Сервер объекта; неизвестный объект ищется на всех серверах. Запрос не выполняется, если сервер объекта
+// нельзя определить однозначно
+func (m *MultiClient) site(ctx context.Context, siteId, userName string) (Target, error) {
+	if t, err := m.Registry.ForSite(siteId); err == nil {
+		return t, nil
+	}
+
+	site, err := m.GetSites(ctx, andromeda.GetSitesInput{Id: siteId, UserName: userName})
+	switch {
+	case err == nil:
+	case site.Server == "" && !errors.Is(err, ErrSiteAmbiguous):
+		return Target{}, &andromeda.Error{Code: andromeda.CodeSiteNotFound, Detail: siteId, Err: err}
+	default:
+		return Target{}, err
+	}
+
+	return m.Registry.Server(site.Server)
+}
@@ -0,0 +1,178 @@
+package multi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+// Поведение тестового сервера Андромеды
+type serverMode int
+
+const (
+	hasSite serverMode = iota //Объект есть на сервере
+	noSite                    //Сервер отвечает, что объекта нет
+	down                      //Сервер недоступен
+)
+
+// Количество запросов к серверам по имени сервера
+type hitCounter struct {
+	mu   sync.Mutex
+	hits map[string]int
+}
+
+func (h *hitCounter) add(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hits[name]++
+}
+
+func (h *hitCounter) get(name string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.hits[name]
+}
+
+// Тестовый сервер Андромеды, считающий запросы в hits
+func newServer(t *testing.T, name string, mode serverMode, hits *hitCounter) Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.add(name)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case mode == noSite:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"Message":"Объект не найден","SpResultCode":1}`))
+		case r.URL.Path == "/Sites":
+			w.Write([]byte(`{"Id":"guid-1","AccountNumber":101}`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	if mode == down {
+		srv.Close()
+	}
+
+	return Server{Name: name, Config: andromeda.Config{Host: srv.URL, ApiKey: "key"}}
+}
+
+func newMultiClient(t *testing.T, modes []serverMode) (*MultiClient, *hitCounter) {
+	t.Helper()
+
+	hits := &hitCounter{hits: map[string]int{}}
+	var servers []Server
+	for idx, mode := range modes {
+		servers = append(servers, newServer(t, "s"+strconv.Itoa(idx+1), mode, hits))
+	}
+	r, err := NewRegistry(nil, servers...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &MultiClient{Registry: r}, hits
+}
+
+// Код ошибки; для Errors - код ошибки первого сервера
+func errCode(err error) andromeda.ErrorCode {
+	var errs Errors
+	if code := andromeda.CodeOf(err); code != "" || !errors.As(err, &errs) {
+		return code
+	}
+
+	return andromeda.CodeOf(errs[0])
+}
+
+func TestMultiClientGetSites(t *testing.T) {
+	tests := []struct {
+		name    string
+		modes   []serverMode
+		server  string //Сервер найденного объекта
+		code    andromeda.ErrorCode
+		mapped  bool
+		errText string //Текст, который должен быть в ошибке
+	}{
+		{name: "объект на одном сервере", modes: []serverMode{noSite, hasSite, noSite}, server: "s2", mapped: true},
+		{name: "объект на нескольких серверах", modes: []serverMode{hasSite, noSite, hasSite}, code: andromeda.CodeSiteAmbiguous, errText: "s1, s3"},
+		{name: "объект найден, другой сервер недоступен", modes: []serverMode{hasSite, down}, server: "s1", code: andromeda.CodeRequestFailed, errText: "s2"},
+		{name: "объекта нет", modes: []serverMode{noSite, noSite}, code: andromeda.CodeServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := newMultiClient(t, tt.modes)
+
+			site, err := m.GetSites(context.Background(), andromeda.GetSitesInput{Id: "101"})
+			if got := errCode(err); got != tt.code {
+				t.Fatalf("код ошибки %q, ожидался %q (%v)", got, tt.code, err)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.errText) {
+				t.Fatalf("ошибка не содержит %q: %v", tt.errText, err)
+			}
+			if site.Server != tt.server {
+				t.Fatalf("сервер %q, ожидался %q", site.Server, tt.server)
+			}
+			if mapped := len(m.Registry.Sites()) > 0; mapped != tt.mapped {
+				t.Fatalf("объект привязан: %v, ожидалось %v (%v)", mapped, tt.mapped, m.Registry.Sites())
+			}
+		})
+	}
+}
+
+// Запрос по объекту выполняется только на сервере, к которому объект однозначно привязан
+func TestMultiClientSiteRouting(t *testing.T) {
+	tests := []struct {
+		name  string
+		modes []serverMode
+		code  andromeda.ErrorCode
+		hits  map[string]int
+	}{
+		{name: "объект на одном сервере", modes: []serverMode{noSite, hasSite}, hits: map[string]int{"s1": 1, "s2": 2}},
+		{name: "объект на нескольких серверах", modes: []serverMode{hasSite, hasSite}, code: andromeda.CodeSiteAmbiguous, hits: map[string]int{"s1": 1, "s2": 1}},
+		{name: "другой сервер недоступен", modes: []serverMode{hasSite, down}, code: andromeda.CodeRequestFailed, hits: map[string]int{"s1": 1}},
+		{name: "объекта нет", modes: []serverMode{noSite, noSite}, code: andromeda.CodeSiteNotFound, hits: map[string]int{"s1": 1, "s2": 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, hits := newMultiClient(t, tt.modes)
+
+			_, err := m.GetParts(context.Background(), andromeda.GetPartsInput{SiteId: "guid-1"})
+			if got := errCode(err); got != tt.code {
+				t.Fatalf("код ошибки %q, ожидался %q (%v)", got, tt.code, err)
+			}
+			for name, want := range tt.hits {
+				if got := hits.get(name); got != want {
+					t.Errorf("запросов к %s: %d, ожидалось %d", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFanOutErrors(t *testing.T) {
+	m, _ := newMultiClient(t, []serverMode{hasSite, down, noSite})
+
+	sites, err := FanOut(context.Background(), m.Registry, 0, func(ctx context.Context, t Target) (andromeda.GetSitesResponse, error) {
+		return t.GetSites(ctx, andromeda.GetSitesInput{Id: "101"})
+	})
+	if len(sites) != 1 || sites[0].Server != "s1" {
+		t.Fatalf("результаты %+v, ожидался объект с s1", sites)
+	}
+
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 2 || errs[0].Server != "s2" || errs[1].Server != "s3" {
+		t.Fatalf("ошибки %v, ожидались ошибки s2 и s3", err)
+	}
+	if andromeda.CodeOf(errs[0]) != andromeda.CodeRequestFailed || andromeda.CodeOf(errs[1]) != andromeda.CodeServerError {
+		t.Fatalf("коды ошибок %q и %q", andromeda.CodeOf(errs[0]), andromeda.CodeOf(errs[1]))
+	}
+}
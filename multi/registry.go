@@ -0,0 +1,194 @@
+// Пакет multi направляет запросы к нескольким серверам Андромеды (например, по одному на регион)
+// по имени сервера, арендатору или идентификатору объекта.
+package multi
+
+import (
+	"strings"
+	"sync"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+// Ошибки поиска сервера; сравниваются через errors.Is по коду
+var (
+	ErrUnknownServer = &andromeda.Error{Code: andromeda.CodeServerUnknown}
+	ErrUnknownTenant = &andromeda.Error{Code: andromeda.CodeTenantUnknown}
+	ErrUnknownSite   = &andromeda.Error{Code: andromeda.CodeSiteUnmapped}
+	ErrSiteAmbiguous = &andromeda.Error{Code: andromeda.CodeSiteAmbiguous}
+)
+
+type (
+	//Профиль сервера Андромеды
+	Server struct {
+		Name    string            //Имя сервера, например «msk»
+		Tenants []string          //Арендаторы, запросы которых направляются на этот сервер
+		Config  andromeda.Config  //Адрес сервера и API ключ
+		Client  *andromeda.Client //Клиент для этого сервера (необязательное поле, по умолчанию общий клиент реестра)
+	}
+
+	//Реестр серверов с привязкой арендаторов и объектов
+	Registry struct {
+		mu      sync.RWMutex
+		client  *andromeda.Client
+		servers map[string]Server
+		order   []string
+		tenants map[string]string
+		sites   map[string]string
+	}
+)
+
+// Создание реестра. client используется для серверов без собственного клиента; nil - клиент по умолчанию
+func NewRegistry(client *andromeda.Client, servers ...Server) (*Registry, error) {
+	if client == nil {
+		client = andromeda.NewClient()
+	}
+	r := &Registry{
+		client:  client,
+		servers: map[string]Server{},
+		tenants: map[string]string{},
+		sites:   map[string]string{},
+	}
+	for _, s := range servers {
+		if err := r.Add(s); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Регистрация сервера. Имя сервера и его арендаторы должны быть уникальными
+func (r *Registry) Add(s Server) error {
+	if s.Name == "" {
+		return &andromeda.Error{Code: andromeda.CodeServerName}
+	}
+	if s.Config.Host == "" {
+		return &andromeda.Error{Code: andromeda.CodeHostRequired, Detail: s.Name}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.servers[s.Name]; ok {
+		return &andromeda.Error{Code: andromeda.CodeServerDuplicate, Detail: s.Name}
+	}
+	for _, tenant := range s.Tenants {
+		if other, ok := r.tenants[tenant]; ok {
+			return &andromeda.Error{Code: andromeda.CodeTenantDuplicate, Detail: tenant + " -> " + other}
+		}
+	}
+
+	r.servers[s.Name] = s
+	r.order = append(r.order, s.Name)
+	for _, tenant := range s.Tenants {
+		r.tenants[tenant] = s.Name
+	}
+
+	return nil
+}
+
+// Привязка объекта (номера или идентификатора) к серверу
+func (r *Registry) MapSite(siteId, server string) error {
+	return r.MapSites(map[string]string{siteId: server})
+}
+
+// Привязка объектов к серверам: идентификатор или номер объекта -> имя сервера
+func (r *Registry) MapSites(sites map[string]string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, server := range sites {
+		if _, ok := r.servers[server]; !ok {
+			return &andromeda.Error{Code: andromeda.CodeServerUnknown, Detail: server}
+		}
+	}
+	for siteId, server := range sites {
+		r.sites[siteKey(siteId)] = server
+	}
+
+	return nil
+}
+
+// Имена серверов в порядке регистрации
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string(nil), r.order...)
+}
+
+// Копия привязки объектов к серверам
+func (r *Registry) Sites() map[string]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sites := make(map[string]string, len(r.sites))
+	for k, v := range r.sites {
+		sites[k] = v
+	}
+
+	return sites
+}
+
+// Сервер по имени
+func (r *Registry) Server(name string) (Target, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.servers[name]
+	if !ok {
+		return Target{}, &andromeda.Error{Code: andromeda.CodeServerUnknown, Detail: name}
+	}
+
+	return r.target(s), nil
+}
+
+// Сервер, к которому привязан арендатор
+func (r *Registry) ForTenant(tenant string) (Target, error) {
+	r.mu.RLock()
+	name, ok := r.tenants[tenant]
+	r.mu.RUnlock()
+	if !ok {
+		return Target{}, &andromeda.Error{Code: andromeda.CodeTenantUnknown, Detail: tenant}
+	}
+
+	return r.Server(name)
+}
+
+// Сервер, к которому привязан объект
+func (r *Registry) ForSite(siteId string) (Target, error) {
+	r.mu.RLock()
+	name, ok := r.sites[siteKey(siteId)]
+	r.mu.RUnlock()
+	if !ok {
+		return Target{}, &andromeda.Error{Code: andromeda.CodeSiteUnmapped, Detail: siteId}
+	}
+
+	return r.Server(name)
+}
+
+// Все серверы в порядке регистрации
+func (r *Registry) Targets() []Target {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	targets := make([]Target, len(r.order))
+	for idx, name := range r.order {
+		targets[idx] = r.target(r.servers[name])
+	}
+
+	return targets
+}
+
+func (r *Registry) target(s Server) Target {
+	client := s.Client
+	if client == nil {
+		client = r.client
+	}
+
+	return Target{Name: s.Name, Config: s.Config, Client: client}
+}
+
+// Идентификаторы объектов (GUID) сравниваются без учёта регистра
+func siteKey(siteId string) string {
+	return strings.ToLower(strings.TrimSpace(siteId))
+}
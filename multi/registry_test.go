@@ -0,0 +1,83 @@
+package multi
+
+import (
+	"errors"
+	"testing"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+func TestRegistryAdd(t *testing.T) {
+	msk := Server{Name: "msk", Tenants: []string{"t1"}, Config: andromeda.Config{Host: "https://msk.local"}}
+
+	tests := []struct {
+		name   string
+		server Server
+		code   andromeda.ErrorCode
+	}{
+		{name: "новый сервер", server: Server{Name: "spb", Tenants: []string{"t2"}, Config: andromeda.Config{Host: "https://spb.local"}}},
+		{name: "без имени", server: Server{Config: andromeda.Config{Host: "https://spb.local"}}, code: andromeda.CodeServerName},
+		{name: "без адреса", server: Server{Name: "spb"}, code: andromeda.CodeHostRequired},
+		{name: "повтор имени", server: Server{Name: "msk", Config: andromeda.Config{Host: "https://msk2.local"}}, code: andromeda.CodeServerDuplicate},
+		{name: "арендатор другого сервера", server: Server{Name: "spb", Tenants: []string{"t1"}, Config: andromeda.Config{Host: "https://spb.local"}}, code: andromeda.CodeTenantDuplicate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRegistry(nil, msk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := andromeda.CodeOf(r.Add(tt.server)); got != tt.code {
+				t.Fatalf("код ошибки %q, ожидался %q", got, tt.code)
+			}
+		})
+	}
+}
+
+func TestRegistryLookup(t *testing.T) {
+	r, err := NewRegistry(nil,
+		Server{Name: "msk", Tenants: []string{"t1"}, Config: andromeda.Config{Host: "https://msk.local"}},
+		Server{Name: "spb", Config: andromeda.Config{Host: "https://spb.local"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.MapSite("GUID-1", "spb"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.MapSite("guid-2", "nsk"); !errors.Is(err, ErrUnknownServer) {
+		t.Fatalf("ошибка %v, ожидалась ErrUnknownServer", err)
+	}
+
+	tests := []struct {
+		name   string
+		lookup func() (Target, error)
+		server string
+		err    error
+	}{
+		{name: "объект без учёта регистра", lookup: func() (Target, error) { return r.ForSite(" guid-1") }, server: "spb"},
+		{name: "непривязанный объект", lookup: func() (Target, error) { return r.ForSite("guid-2") }, err: ErrUnknownSite},
+		{name: "арендатор", lookup: func() (Target, error) { return r.ForTenant("t1") }, server: "msk"},
+		{name: "неизвестный арендатор", lookup: func() (Target, error) { return r.ForTenant("t2") }, err: ErrUnknownTenant},
+		{name: "неизвестный сервер", lookup: func() (Target, error) { return r.Server("nsk") }, err: ErrUnknownServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := tt.lookup()
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("ошибка %v, ожидалась %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if target.Name != tt.server || target.Client == nil {
+				t.Fatalf("сервер %q, ожидался %q", target.Name, tt.server)
+			}
+		})
+	}
+}
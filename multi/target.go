@@ -0,0 +1,86 @@
+package multi
+
+import (
+	"context"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+// Выбранный сервер: методы SDK, в которых Config входной структуры заменяется параметрами сервера
+type Target struct {
+	Name   string
+	Config andromeda.Config
+	Client *andromeda.Client
+}
+
+// Запрос метода GetSites
+func (t Target) GetSites(ctx context.Context, input andromeda.GetSitesInput) (andromeda.GetSitesResponse, error) {
+	input.Config = t.Config
+	return t.Client.GetSites(ctx, input)
+}
+
+// Запрос метода GetCustomers
+func (t Target) Customers(ctx context.Context, input andromeda.GetCustomersInput) ([]andromeda.GetCustomerResponse, error) {
+	input.Config = t.Config
+	return t.Client.Customers(ctx, input)
+}
+
+// Запрос метода GetCustomer
+func (t Target) GetCustomer(ctx context.Context, input andromeda.GetCustomerInput) (andromeda.GetCustomerResponse, error) {
+	input.Config = t.Config
+	return t.Client.GetCustomer(ctx, input)
+}
+
+// Запрос метода PostCheckPanic
+func (t Target) PostCheckPanic(ctx context.Context, input andromeda.PostCheckPanicInput) (andromeda.PostCheckPanicResponse, error) {
+	input.Config = t.Config
+	return t.Client.PostCheckPanic(ctx, input)
+}
+
+// Запрос метода GetCheckPanic
+func (t Target) GetCheckPanic(ctx context.Context, input andromeda.GetCheckPanicInput) (andromeda.GetCheckPanicResponse, error) {
+	input.Config = t.Config
+	return t.Client.GetCheckPanic(ctx, input)
+}
+
+// Запрос метода GetUsersMyAlarm
+func (t Target) GetUsersMyAlarm(ctx context.Context, input andromeda.GetUsersMyAlarmInput) ([]andromeda.UserMyAlarmResponse, error) {
+	input.Config = t.Config
+	return t.Client.GetUsersMyAlarm(ctx, input)
+}
+
+// Запрос метода PutChangeUserMyAlarm
+func (t Target) PutChangeUserMyAlarm(ctx context.Context, input andromeda.PutChangeUserMyAlarmInput) (andromeda.PutChangeUserMyAlarmResponse, error) {
+	input.Config = t.Config
+	return t.Client.PutChangeUserMyAlarm(ctx, input)
+}
+
+// Запрос метода GetUserObjectMyAlarm
+func (t Target) GetUserObjectMyAlarm(ctx context.Context, input andromeda.GetUserObjectMyAlarmInput) ([]andromeda.GetUserObjectMyAlarmResponse, error) {
+	input.Config = t.Config
+	return t.Client.GetUserObjectMyAlarm(ctx, input)
+}
+
+// Запрос метода PutChangeKTSUserMyAlarm
+func (t Target) PutChangeKTSUserMyAlarm(ctx context.Context, input andromeda.PutChangeKTSUserMyAlarmInput) error {
+	input.Config = t.Config
+	return t.Client.PutChangeKTSUserMyAlarm(ctx, input)
+}
+
+// Запрос метода GetParts
+func (t Target) GetParts(ctx context.Context, input andromeda.GetPartsInput) ([]andromeda.GetPartsResponse, error) {
+	input.Config = t.Config
+	return t.Client.GetParts(ctx, input)
+}
+
+// Запрос метода GetZones
+func (t Target) GetZones(ctx context.Context, input andromeda.GetZonesInput) ([]andromeda.GetZonesResponse, error) {
+	input.Config = t.Config
+	return t.Client.GetZones(ctx, input)
+}
+
+// Профиль пользователя MyAlarm по телефону
+func (t Target) GetPhoneProfile(ctx context.Context, input andromeda.GetPhoneProfileInput) ([]andromeda.PhoneProfileEntry, error) {
+	input.Config = t.Config
+	return t.Client.GetPhoneProfile(ctx, input)
+}
@@ -0,0 +1,150 @@
+package andromeda
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+)
+
+type (
+	//Входная структура любого метода: проверка собственных полей и общие параметры Config
+	input interface {
+		validate() error
+		validateConfig(keyRequired bool) error
+		config() Config
+	}
+
+	//Описание метода API: как из входной структуры построить запрос и как разобрать ответ
+	endpoint[In input, Out any] struct {
+		name   string                 //Имя метода SDK, используется в ошибках и проверке структуры ответа
+		method string                 //HTTP метод
+		path   string                 //Путь относительно адреса сервера
+		query  func(in In) url.Values //Параметры строки запроса
+		body   func(in In) any        //Тело запроса, кодируется в JSON (необязательное поле)
+
+		emptyOK bool //Пустой ответ допустим, Out остаётся нулевым
+		discard bool //Ответ не разбирается
+
+		audit func(in In) AuditRecord //Начальные данные записи журнала; задаётся для изменяющих методов
+	}
+)
+
+// Выполнение метода API: проверка входных данных, построение запроса, HTTP запрос и разбор ответа.
+// В режиме пробного запуска изменяющий запрос не отправляется
+func call[In input, Out any](ctx context.Context, c *Client, e endpoint[In, Out], in In) (Out, error) {
+	var out Out
+
+	if err := validateInput(in, c.credentials == nil, c.lang); err != nil {
+		return out, err
+	}
+
+	req, err := e.generateRequest(in, c.lang)
+	if err != nil {
+		return out, err
+	}
+
+	if c.isDryRun(ctx, e.method) {
+		planned := req.planned(e.method)
+		if c.dryRunReport != nil {
+			c.dryRunReport(planned)
+		}
+		return out, &DryRunError{Request: planned, Lang: c.lang}
+	}
+
+	start := time.Now()
+	out, err = e.exec(ctx, c, req)
+
+	if c.audit != nil && e.audit != nil {
+		var resp any
+		if !e.discard {
+			resp = out
+		}
+		c.recordAudit(ctx, e.audit(in), req, start, resp, err)
+	}
+
+	return out, err
+}
+
+// HTTP запрос и разбор ответа
+func (e endpoint[In, Out]) exec(ctx context.Context, c *Client, req request) (Out, error) {
+	var out Out
+
+	body, err := c.doHTTP(ctx, e.method, req)
+	if err != nil {
+		return out, err
+	}
+
+	if e.discard || (e.emptyOK && len(body) == 0) {
+		return out, nil
+	}
+
+	if err := c.decode(e.name, body, &out); err != nil {
+		var zero Out
+		return zero, err
+	}
+
+	return out, nil
+}
+
+// Генерация запроса метода
+func (e endpoint[In, Out]) generateRequest(in In, lang Lang) (request, error) {
+	cfg := in.config()
+
+	baseURL, err := url.Parse(cfg.Host + e.path)
+	if err != nil {
+		return request{}, &Error{Code: CodeHostInvalid, Err: err, Lang: lang}
+	}
+	if e.query != nil {
+		baseURL.RawQuery = e.query(in).Encode()
+	}
+
+	body := []byte{}
+	if e.body != nil {
+		if body, err = json.Marshal(e.body(in)); err != nil {
+			return request{}, &Error{Code: CodeRequestBuildFailed, Err: err, Lang: lang}
+		}
+	}
+
+	path := e.path
+	if baseURL.RawQuery != "" {
+		path += "?" + baseURL.RawQuery
+	}
+
+	return request{
+		name:   e.name,
+		URL:    baseURL.String(),
+		host:   cfg.Host,
+		path:   path,
+		body:   body,
+		apiKey: cfg.ApiKey,
+	}, nil
+}
+
+// Параметры запроса из пар имя/значение. Имя пользователя добавляется, только если задано
+func params(userName string, kv ...string) url.Values {
+	param := url.Values{}
+	for idx := 0; idx+1 < len(kv); idx += 2 {
+		param.Add(kv[idx], kv[idx+1])
+	}
+	if userName != "" {
+		param.Add("userName", userName)
+	}
+
+	return param
+}
+
+func (c Config) config() Config {
+	return c
+}
+
+// Проверка заполнения параметров, общих для всех запросов. API ключ не обязателен, если у клиента задан CredentialsProvider
+func (c Config) validateConfig(keyRequired bool) error {
+	var v validator
+	if keyRequired {
+		v.required("Config.ApiKey", c.ApiKey, CodeApiKeyRequired)
+	}
+	v.required("Config.Host", c.Host, CodeHostRequired)
+
+	return v.err()
+}
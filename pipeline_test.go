@@ -0,0 +1,137 @@
+package andromeda
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestEndpointRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		call   func(c *Client, cfg Config) error
+		method string
+		path   string
+		query  url.Values
+		body   string
+	}{
+		{
+			name: "GetSites без имени пользователя",
+			call: func(c *Client, cfg Config) error {
+				_, err := c.GetSites(context.Background(), GetSitesInput{Id: "s1", Config: cfg})
+				return err
+			},
+			method: http.MethodGet,
+			path:   endpointGetSites,
+			query:  url.Values{"id": {"s1"}},
+		},
+		{
+			name: "PostCheckPanic с интервалом и именем пользователя",
+			call: func(c *Client, cfg Config) error {
+				_, err := c.PostCheckPanic(context.Background(), PostCheckPanicInput{SiteId: "s1", CheckInterval: 60, UserName: "op", Config: cfg})
+				return err
+			},
+			method: http.MethodPost,
+			path:   endpointCheckPanic,
+			query:  url.Values{"siteId": {"s1"}, "stopOnEvent": {"True"}, "checkInterval": {"60"}, "userName": {"op"}},
+		},
+		{
+			name: "PutChangeKTSUserMyAlarm",
+			call: func(c *Client, cfg Config) error {
+				return c.PutChangeKTSUserMyAlarm(context.Background(), PutChangeKTSUserMyAlarmInput{CustId: "c1", IsPanic: true, Config: cfg})
+			},
+			method: http.MethodPut,
+			path:   endpointMyAlarm,
+			query:  url.Values{"custId": {"c1"}, "isPanic": {"true"}},
+		},
+		{
+			name: "GetUserObjectMyAlarm передаёт телефон в теле",
+			call: func(c *Client, cfg Config) error {
+				_, err := c.GetUserObjectMyAlarm(context.Background(), GetUserObjectMyAlarmInput{Phone: "+79001234567", UserName: "op", Config: cfg})
+				return err
+			},
+			method: http.MethodGet,
+			path:   endpointGetUserObjectMyAlarm,
+			query:  url.Values{"userName": {"op"}},
+			body:   `{"Phone":"+79001234567"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *http.Request
+			var body []byte
+			srv := newCountingServer(t, func(w http.ResponseWriter, r *http.Request) {
+				got = r
+				body, _ = io.ReadAll(r.Body)
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{}`))
+			})
+
+			tt.call(NewClient(), Config{Host: srv.URL, ApiKey: "key"})
+
+			if got == nil {
+				t.Fatal("запрос не отправлен")
+			}
+			if got.Method != tt.method || got.URL.Path != tt.path {
+				t.Errorf("запрос %s %s, ожидался %s %s", got.Method, got.URL.Path, tt.method, tt.path)
+			}
+			if !reflect.DeepEqual(got.URL.Query(), tt.query) {
+				t.Errorf("параметры %v, ожидалось %v", got.URL.Query(), tt.query)
+			}
+			if string(body) != tt.body {
+				t.Errorf("тело %q, ожидалось %q", body, tt.body)
+			}
+			if key := got.Header.Get("apiKey"); key != "key" {
+				t.Errorf("API ключ %q", key)
+			}
+		})
+	}
+}
+
+func TestEndpointResponse(t *testing.T) {
+	cfg := func(srv *countingServer) Config { return Config{Host: srv.URL, ApiKey: "key"} }
+
+	t.Run("пустой ответ допустим при emptyOK", func(t *testing.T) {
+		srv := newCountingServer(t, jsonHandler(""))
+		resp, err := NewClient().PutChangeUserMyAlarm(context.Background(), PutChangeUserMyAlarmInput{CustId: "c1", Role: "user", Config: cfg(srv)})
+		if err != nil || resp.Message != "" {
+			t.Fatalf("ответ %+v, ошибка %v", resp, err)
+		}
+	})
+
+	t.Run("непустой ответ при emptyOK разбирается", func(t *testing.T) {
+		srv := newCountingServer(t, jsonHandler(`{"Message":"ok"}`))
+		resp, err := NewClient().PutChangeUserMyAlarm(context.Background(), PutChangeUserMyAlarmInput{CustId: "c1", Role: "user", Config: cfg(srv)})
+		if err != nil || resp.Message != "ok" {
+			t.Fatalf("ответ %+v, ошибка %v", resp, err)
+		}
+	})
+
+	t.Run("пустой ответ без emptyOK - ошибка", func(t *testing.T) {
+		srv := newCountingServer(t, jsonHandler(""))
+		if _, err := NewClient().GetCheckPanic(context.Background(), GetCheckPanicInput{CheckPanicId: "p1", Config: cfg(srv)}); err == nil {
+			t.Fatal("ожидалась ошибка разбора пустого ответа")
+		}
+	})
+
+	t.Run("ответ не разбирается при discard", func(t *testing.T) {
+		srv := newCountingServer(t, jsonHandler("не JSON"))
+		if err := NewClient().PutChangeKTSUserMyAlarm(context.Background(), PutChangeKTSUserMyAlarmInput{CustId: "c1", Config: cfg(srv)}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("ошибка проверки не отправляет запрос", func(t *testing.T) {
+		srv := newCountingServer(t, jsonHandler(`{}`))
+		if _, err := NewClient().PutChangeUserMyAlarm(context.Background(), PutChangeUserMyAlarmInput{CustId: "c1", Role: "owner", Config: cfg(srv)}); err == nil {
+			t.Fatal("ожидалась ошибка проверки роли")
+		}
+		if hits := srv.hits.Load(); hits != 0 {
+			t.Fatalf("отправлено запросов: %d", hits)
+		}
+	})
+}
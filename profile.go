@@ -0,0 +1,127 @@
+package andromeda
+
+import (
+	"context"
+	"errors"
+)
+
+type (
+	//Входная структура для метода GetPhoneProfile
+	GetPhoneProfileInput struct {
+		Phone       string //Телефон пользователя MyAlarm в формате +7XXXXXXXXXX
+		UserName    string //Имя пользователя, от которого делается запрос (необязательное поле)
+		Concurrency int    //Количество одновременных запросов карточек объектов и ответственных лиц (по умолчанию 4)
+		Config
+	}
+
+	//Объект пользователя MyAlarm с данными карточки объекта и ответственного лица
+	PhoneProfileEntry struct {
+		ObjectGUID     string //Идентификатор объекта
+		SiteName       string //Название объекта
+		Address        string //Адрес объекта
+		AccountNumber  int    //Номер объекта
+		IsStateArm     bool   //Состояние объекта: взят/снят
+		IsStateAlarm   bool   //Состояние объекта: в тревоге - да/нет
+		IsStatePartArm bool   //Состояние объекта: взят частично - да/нет
+		CustomerID     string //Идентификатор ответственного лица
+		CustomerName   string //ФИО ответственного лица
+		Role           string //Роль пользователя MyAlarm
+		IsPanic        bool   //Разрешён или запрещён КТС
+	}
+
+	//Карточка объекта или ответственного лица, запрашиваемая для GetPhoneProfile
+	profileFetch struct {
+		id       string
+		customer bool //Карточка ответственного лица, иначе объекта
+	}
+
+	//Полученная карточка: заполнено поле, соответствующее profileFetch.customer
+	profileCard struct {
+		site     GetSitesResponse
+		customer GetCustomerResponse
+	}
+)
+
+// Получение всех объектов и ролей пользователя MyAlarm по номеру телефона.
+// Карточки объектов и ответственных лиц запрашиваются через Bulk не более чем Concurrency запросами одновременно,
+// каждая не более одного раза. При первой ошибке оставшиеся карточки не запрашиваются.
+func (c *Client) GetPhoneProfile(ctx context.Context, input GetPhoneProfileInput) ([]PhoneProfileEntry, error) {
+	objects, err := c.GetUserObjectMyAlarm(ctx, GetUserObjectMyAlarmInput{
+		Phone:    input.Phone,
+		UserName: input.UserName,
+		Config:   input.Config,
+	})
+	if err != nil {
+		return []PhoneProfileEntry{}, err
+	}
+
+	var (
+		fetches   []profileFetch
+		sites     = map[string]GetSitesResponse{}
+		customers = map[string]GetCustomerResponse{}
+	)
+	for _, o := range objects {
+		if _, ok := sites[o.ObjectGUID]; !ok {
+			sites[o.ObjectGUID] = GetSitesResponse{}
+			fetches = append(fetches, profileFetch{id: o.ObjectGUID})
+		}
+		if _, ok := customers[o.CustomerID]; !ok {
+			customers[o.CustomerID] = GetCustomerResponse{}
+			fetches = append(fetches, profileFetch{id: o.CustomerID, customer: true})
+		}
+	}
+
+	fetch := func(ctx context.Context, f profileFetch) (profileCard, error) {
+		if f.customer {
+			cust, err := c.GetCustomer(ctx, GetCustomerInput{Id: f.id, UserName: input.UserName, Config: input.Config})
+			if err != nil {
+				return profileCard{}, c.error(CodeCustomerFetch, err, f.id)
+			}
+			return profileCard{customer: cust}, nil
+		}
+
+		site, err := c.GetSites(ctx, GetSitesInput{Id: f.id, UserName: input.UserName, Config: input.Config})
+		if err != nil {
+			return profileCard{}, c.error(CodeSiteFetch, err, f.id)
+		}
+		return profileCard{site: site}, nil
+	}
+
+	results, _ := Bulk(ctx, fetches, fetch, BulkOptions{Concurrency: input.Concurrency, StopOnError: true})
+	for _, r := range results {
+		if r.Err != nil && !errors.Is(r.Err, ErrBulkSkipped) {
+			return []PhoneProfileEntry{}, r.Err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return []PhoneProfileEntry{}, err
+	}
+	for _, r := range results {
+		if r.Input.customer {
+			customers[r.Input.id] = r.Output.customer
+		} else {
+			sites[r.Input.id] = r.Output.site
+		}
+	}
+
+	resp := make([]PhoneProfileEntry, 0, len(objects))
+	for _, o := range objects {
+		site := sites[o.ObjectGUID]
+		cust := customers[o.CustomerID]
+		resp = append(resp, PhoneProfileEntry{
+			ObjectGUID:     o.ObjectGUID,
+			SiteName:       site.Name,
+			Address:        site.Address,
+			AccountNumber:  site.AccountNumber,
+			IsStateArm:     site.IsStateArm,
+			IsStateAlarm:   site.IsStateAlarm,
+			IsStatePartArm: site.IsStatePartArm,
+			CustomerID:     o.CustomerID,
+			CustomerName:   cust.ObjCustName,
+			Role:           o.Role,
+			IsPanic:        o.IsPanic,
+		})
+	}
+
+	return resp, nil
+}
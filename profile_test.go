@@ -0,0 +1,155 @@
+package andromeda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Тестовый сервер для GetPhoneProfile: объекты пользователя, карточки объектов и ответственных лиц.
+// Запросы карточек считаются по идентификатору, карточка failId отвечает ошибкой 500
+type profileServer struct {
+	*countingServer
+	objects string
+	failId  string
+	delay   time.Duration
+
+	mu       sync.Mutex
+	fetched  map[string]int
+	inFlight int
+	peak     int //Наибольшее количество одновременных запросов карточек
+}
+
+func newProfileServer(t *testing.T, objects, failId string) *profileServer {
+	s := &profileServer{objects: objects, failId: failId, fetched: map[string]int{}}
+	s.countingServer = newCountingServer(t, s.handle)
+	return s
+}
+
+func (s *profileServer) handle(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == endpointGetUserObjectMyAlarm {
+		jsonHandler(s.objects)(w, r)
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	s.mu.Lock()
+	s.fetched[r.URL.Path+"?"+id]++
+	s.inFlight++
+	s.peak = max(s.peak, s.inFlight)
+	s.mu.Unlock()
+
+	time.Sleep(s.delay)
+
+	s.mu.Lock()
+	s.inFlight--
+	s.mu.Unlock()
+
+	switch {
+	case id == s.failId:
+		w.WriteHeader(http.StatusInternalServerError)
+	case r.URL.Path == endpointGetSites:
+		jsonHandler(fmt.Sprintf(`{"Id":%q,"Name":"Объект %s","Address":"Адрес %s","AccountNumber":101,"IsStateArm":true}`, id, id, id))(w, r)
+	case r.URL.Path == endpointGetCustomers:
+		jsonHandler(fmt.Sprintf(`{"Id":%q,"ObjCustName":"Ответственный %s"}`, id, id))(w, r)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestGetPhoneProfile(t *testing.T) {
+	const objects = `[
+		{"ObjectGUID":"s1","CustomerID":"c1","Role":"admin","IsPanic":true},
+		{"ObjectGUID":"s1","CustomerID":"c2","Role":"user"},
+		{"ObjectGUID":"s2","CustomerID":"c1","Role":"user"}
+	]`
+
+	entry := func(site, cust, role string, isPanic bool) PhoneProfileEntry {
+		return PhoneProfileEntry{
+			ObjectGUID:    site,
+			SiteName:      "Объект " + site,
+			Address:       "Адрес " + site,
+			AccountNumber: 101,
+			IsStateArm:    true,
+			CustomerID:    cust,
+			CustomerName:  "Ответственный " + cust,
+			Role:          role,
+			IsPanic:       isPanic,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		objects string
+		failId  string
+		want    []PhoneProfileEntry
+		fetched int //Различных карточек объектов и ответственных лиц
+		code    ErrorCode
+	}{
+		{
+			name:    "объекты дополняются карточками",
+			objects: objects,
+			want:    []PhoneProfileEntry{entry("s1", "c1", "admin", true), entry("s1", "c2", "user", false), entry("s2", "c1", "user", false)},
+			fetched: 4,
+		},
+		{name: "у телефона нет объектов", objects: `[]`, want: []PhoneProfileEntry{}},
+		{name: "ошибка карточки объекта", objects: objects, failId: "s2", code: CodeSiteFetch},
+		{name: "ошибка карточки ответственного", objects: objects, failId: "c2", code: CodeCustomerFetch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newProfileServer(t, tt.objects, tt.failId)
+			got, err := NewClient().GetPhoneProfile(context.Background(), GetPhoneProfileInput{Phone: "+79001234567", Config: Config{Host: srv.URL, ApiKey: "key"}})
+
+			if CodeOf(err) != tt.code {
+				t.Fatalf("код ошибки %q, ожидался %q (%v)", CodeOf(err), tt.code, err)
+			}
+			if tt.code != "" {
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+					t.Fatalf("ошибка %v не содержит ответ сервера", err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("профиль %+v, ожидался %+v", got, tt.want)
+			}
+
+			// Каждая карточка запрашивается один раз, даже если объект или ответственный встречается несколько раз
+			for key, n := range srv.fetched {
+				if n != 1 {
+					t.Errorf("карточка %s запрошена %d раз", key, n)
+				}
+			}
+			if len(srv.fetched) != tt.fetched {
+				t.Errorf("запрошено карточек %d, ожидалось %d", len(srv.fetched), tt.fetched)
+			}
+		})
+	}
+}
+
+func TestGetPhoneProfileConcurrency(t *testing.T) {
+	var objects []GetUserObjectMyAlarmResponse
+	for idx := 0; idx < 10; idx++ {
+		objects = append(objects, GetUserObjectMyAlarmResponse{ObjectGUID: fmt.Sprintf("s%d", idx), CustomerID: fmt.Sprintf("c%d", idx)})
+	}
+	body, _ := json.Marshal(objects)
+
+	srv := newProfileServer(t, string(body), "")
+	srv.delay = 10 * time.Millisecond
+
+	got, err := NewClient().GetPhoneProfile(context.Background(), GetPhoneProfileInput{Phone: "+79001234567", Concurrency: 3, Config: Config{Host: srv.URL, ApiKey: "key"}})
+	if err != nil || len(got) != len(objects) {
+		t.Fatalf("объектов %d, ошибка %v", len(got), err)
+	}
+	if srv.peak > 3 {
+		t.Fatalf("одновременных запросов %d, ожидалось не больше 3", srv.peak)
+	}
+}
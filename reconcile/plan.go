@@ -0,0 +1,172 @@
+package reconcile
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+const (
+	ActionChangeRole = "PutChangeUserMyAlarm"
+	ActionChangeKTS  = "PutChangeKTSUserMyAlarm"
+)
+
+// Ошибка, возвращаемая Apply, если применение плана не подтверждено или не задан способ подтверждения
+var ErrNotConfirmed = &andromeda.Error{Code: andromeda.CodeNotConfirmed}
+
+type (
+	//Одно изменение плана: вызов PutChangeUserMyAlarm или PutChangeKTSUserMyAlarm
+	Action struct {
+		Type        string `json:"type"`               //ActionChangeRole или ActionChangeKTS
+		SiteId      string `json:"siteId"`             //Идентификатор объекта
+		CustId      string `json:"custId"`             //Идентификатор пользователя
+		PrevRole    string `json:"prevRole,omitempty"` //Текущая роль (для ActionChangeRole)
+		Role        string `json:"role,omitempty"`     //Новая роль (для ActionChangeRole)
+		PrevIsPanic bool   `json:"prevIsPanic"`        //Текущее разрешение КТС (для ActionChangeKTS)
+		IsPanic     bool   `json:"isPanic"`            //Новое разрешение КТС (для ActionChangeKTS)
+	}
+
+	//План изменений доступов MyAlarm
+	Plan struct {
+		Actions []Action `json:"actions"`
+	}
+
+	//Параметры применения плана
+	ApplyOptions struct {
+		DryRun  bool            //Только вывести план, не выполняя запросы
+		Yes     bool            //Применить без подтверждения
+		Confirm func(Plan) bool //Подтверждение применения; без Yes и Confirm план не применяется
+		Output  io.Writer       //Куда выводить план и ход выполнения (необязательное поле)
+	}
+
+	//Результат применения одного изменения
+	ActionResult struct {
+		Action Action
+		Err    error
+	}
+)
+
+// Признак пустого плана
+func (p Plan) Empty() bool {
+	return len(p.Actions) == 0
+}
+
+// Вывод плана в человекочитаемом виде
+func (p Plan) Print(w io.Writer) error {
+	if p.Empty() {
+		_, err := fmt.Fprintln(w, "Изменений нет")
+		return err
+	}
+
+	site := ""
+	for _, a := range p.Actions {
+		if a.SiteId != site {
+			site = a.SiteId
+			if _, err := fmt.Fprintf(w, "Объект %s:\n", site); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "  ~ %s\n", a); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "Всего изменений: %d\n", len(p.Actions))
+	return err
+}
+
+// Вывод плана в формате JSON
+func (p Plan) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(p)
+}
+
+// Описание изменения для вывода
+func (a Action) String() string {
+	if a.Type == ActionChangeKTS {
+		return fmt.Sprintf("пользователь %s: КТС %s -> %s", a.CustId, ktsName(a.PrevIsPanic), ktsName(a.IsPanic))
+	}
+
+	return fmt.Sprintf("пользователь %s: роль %s -> %s", a.CustId, a.PrevRole, a.Role)
+}
+
+func ktsName(isPanic bool) string {
+	if isPanic {
+		return "разрешён"
+	}
+	return "запрещён"
+}
+
+// Применение плана после подтверждения (opts.Yes или opts.Confirm). Изменения выполняются последовательно в порядке плана,
+// при ошибке выполнение продолжается, все ошибки возвращаются в результатах.
+func (r *Reconciler) Apply(ctx context.Context, plan Plan, opts ApplyOptions) ([]ActionResult, error) {
+	out := opts.Output
+	if out == nil {
+		out = io.Discard
+	}
+
+	if err := plan.Print(out); err != nil {
+		return nil, err
+	}
+
+	if opts.DryRun || plan.Empty() {
+		return nil, nil
+	}
+
+	if !opts.Yes && (opts.Confirm == nil || !opts.Confirm(plan)) {
+		return nil, ErrNotConfirmed
+	}
+
+	results := make([]ActionResult, 0, len(plan.Actions))
+	failed := 0
+	for _, a := range plan.Actions {
+		err := r.apply(ctx, a)
+		if err != nil {
+			failed++
+			fmt.Fprintf(out, "ошибка: объект %s, %s: %v\n", a.SiteId, a, err)
+		}
+		results = append(results, ActionResult{Action: a, Err: err})
+	}
+
+	if failed > 0 {
+		return results, &andromeda.Error{Code: andromeda.CodeApplyFailed, Detail: fmt.Sprintf("%d / %d", failed, len(plan.Actions))}
+	}
+
+	return results, nil
+}
+
+// Выполнение одного изменения
+func (r *Reconciler) apply(ctx context.Context, a Action) error {
+	if a.Type == ActionChangeKTS {
+		return r.Client.PutChangeKTSUserMyAlarm(ctx, andromeda.PutChangeKTSUserMyAlarmInput{
+			CustId:   a.CustId,
+			IsPanic:  a.IsPanic,
+			UserName: r.UserName,
+			Config:   r.Config,
+		})
+	}
+
+	_, err := r.Client.PutChangeUserMyAlarm(ctx, andromeda.PutChangeUserMyAlarmInput{
+		CustId:   a.CustId,
+		Role:     a.Role,
+		UserName: r.UserName,
+		Config:   r.Config,
+	})
+	return err
+}
+
+// Подтверждение применения плана вопросом в консоли
+func ConfirmPrompt(in io.Reader, out io.Writer) func(Plan) bool {
+	return func(p Plan) bool {
+		fmt.Fprintf(out, "Применить %d изменений? [y/N]: ", len(p.Actions))
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		return answer == "y" || answer == "yes" || answer == "д" || answer == "да"
+	}
+}
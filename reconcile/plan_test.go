@@ -0,0 +1,117 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+// Андромеда в памяти: пользователи MyAlarm по объекту и выполненные изменения
+type fakeAPI struct {
+	users   map[string][]andromeda.UserMyAlarmResponse
+	calls   []string
+	failFor string //Пользователь, изменение которого завершается ошибкой
+}
+
+func (f *fakeAPI) GetUsersMyAlarm(ctx context.Context, in andromeda.GetUsersMyAlarmInput) ([]andromeda.UserMyAlarmResponse, error) {
+	users, ok := f.users[in.SiteId]
+	if !ok {
+		return nil, errors.New("объект не найден")
+	}
+	return users, nil
+}
+
+func (f *fakeAPI) PutChangeUserMyAlarm(ctx context.Context, in andromeda.PutChangeUserMyAlarmInput) (andromeda.PutChangeUserMyAlarmResponse, error) {
+	f.calls = append(f.calls, "role "+in.CustId+" "+in.Role)
+	if in.CustId == f.failFor {
+		return andromeda.PutChangeUserMyAlarmResponse{}, errors.New("ошибка сервера")
+	}
+	return andromeda.PutChangeUserMyAlarmResponse{}, nil
+}
+
+func (f *fakeAPI) PutChangeKTSUserMyAlarm(ctx context.Context, in andromeda.PutChangeKTSUserMyAlarmInput) error {
+	if in.IsPanic {
+		f.calls = append(f.calls, "kts "+in.CustId+" on")
+	} else {
+		f.calls = append(f.calls, "kts "+in.CustId+" off")
+	}
+	return nil
+}
+
+var testPlan = Plan{Actions: []Action{
+	{Type: ActionChangeRole, SiteId: "s1", CustId: "c1", PrevRole: RoleUser, Role: RoleAdmin},
+	{Type: ActionChangeKTS, SiteId: "s1", CustId: "c2", IsPanic: true},
+}}
+
+func TestApplyConfirmation(t *testing.T) {
+	tests := []struct {
+		name  string
+		plan  Plan
+		opts  ApplyOptions
+		calls int
+		err   error
+	}{
+		{name: "без подтверждения", plan: testPlan, err: ErrNotConfirmed},
+		{name: "подтверждение отклонено", plan: testPlan, opts: ApplyOptions{Confirm: func(Plan) bool { return false }}, err: ErrNotConfirmed},
+		{name: "подтверждено", plan: testPlan, opts: ApplyOptions{Confirm: func(Plan) bool { return true }}, calls: 2},
+		{name: "Yes", plan: testPlan, opts: ApplyOptions{Yes: true}, calls: 2},
+		{name: "DryRun", plan: testPlan, opts: ApplyOptions{DryRun: true, Yes: true}},
+		{name: "пустой план", plan: Plan{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &fakeAPI{}
+			_, err := (&Reconciler{Client: api}).Apply(context.Background(), tt.plan, tt.opts)
+
+			if tt.err != nil && !errors.Is(err, tt.err) || tt.err == nil && err != nil {
+				t.Fatalf("ошибка %v, ожидалась %v", err, tt.err)
+			}
+			if len(api.calls) != tt.calls {
+				t.Fatalf("выполнено изменений %d, ожидалось %d: %v", len(api.calls), tt.calls, api.calls)
+			}
+		})
+	}
+}
+
+func TestApplyContinuesAfterError(t *testing.T) {
+	api := &fakeAPI{failFor: "c1"}
+	var out bytes.Buffer
+
+	results, err := (&Reconciler{Client: api}).Apply(context.Background(), testPlan, ApplyOptions{Yes: true, Output: &out})
+	if andromeda.CodeOf(err) != andromeda.CodeApplyFailed {
+		t.Fatalf("ошибка %v, ожидался код %q", err, andromeda.CodeApplyFailed)
+	}
+	if len(results) != 2 || results[0].Err == nil || results[1].Err != nil {
+		t.Fatalf("результаты %+v", results)
+	}
+	if !strings.Contains(out.String(), "ошибка: объект s1") {
+		t.Fatalf("ошибка не выведена:\n%s", out.String())
+	}
+}
+
+func TestConfirmPrompt(t *testing.T) {
+	tests := []struct {
+		answer string
+		want   bool
+	}{
+		{answer: "y\n", want: true},
+		{answer: "Да\n", want: true},
+		{answer: "\n"},
+		{answer: "n\n"},
+		{answer: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.TrimSpace(tt.answer), func(t *testing.T) {
+			confirm := ConfirmPrompt(strings.NewReader(tt.answer), &bytes.Buffer{})
+			if got := confirm(testPlan); got != tt.want {
+				t.Fatalf("ответ %q: %v, ожидалось %v", tt.answer, got, tt.want)
+			}
+		})
+	}
+}
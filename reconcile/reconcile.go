@@ -0,0 +1,191 @@
+// Пакет reconcile приводит доступы MyAlarm на объектах к состоянию, описанному в файле (YAML или JSON).
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	RoleUser   = "user"
+	RoleAdmin  = "admin"
+	RoleUnlink = "unlink"
+)
+
+type (
+	//Желаемый доступ пользователя MyAlarm на объекте
+	Access struct {
+		Role string `json:"role" yaml:"role"` //Роль пользователя: “user”, “admin” или “unlink”
+		KTS  bool   `json:"kts" yaml:"kts"`   //Разрешено ли использование КТС
+	}
+
+	//Желаемое состояние: идентификатор объекта → идентификатор ответственного → доступ
+	DesiredState map[string]map[string]Access
+
+	//Методы SDK, которые использует Reconciler. Реализуется *andromeda.Client
+	API interface {
+		GetUsersMyAlarm(ctx context.Context, input andromeda.GetUsersMyAlarmInput) ([]andromeda.UserMyAlarmResponse, error)
+		PutChangeUserMyAlarm(ctx context.Context, input andromeda.PutChangeUserMyAlarmInput) (andromeda.PutChangeUserMyAlarmResponse, error)
+		PutChangeKTSUserMyAlarm(ctx context.Context, input andromeda.PutChangeKTSUserMyAlarmInput) error
+	}
+
+	//Сверка текущих доступов MyAlarm с желаемым состоянием
+	Reconciler struct {
+		Client   API
+		Config   andromeda.Config
+		UserName string //Имя пользователя, от которого делаются запросы (необязательное поле)
+		Prune    bool   //Отвязывать пользователей MyAlarm, отсутствующих в желаемом состоянии объекта
+	}
+)
+
+// Чтение желаемого состояния из файла. Формат определяется по расширению: .yaml, .yml или .json
+func LoadFile(path string) (DesiredState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &andromeda.Error{Code: andromeda.CodeFileRead, Detail: path, Err: err}
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ParseJSON(data)
+	case ".yaml", ".yml":
+		return ParseYAML(data)
+	}
+
+	return nil, &andromeda.Error{Code: andromeda.CodeFileFormat, Detail: path}
+}
+
+// Разбор желаемого состояния в формате JSON
+func ParseJSON(data []byte) (DesiredState, error) {
+	var state DesiredState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, &andromeda.Error{Code: andromeda.CodeFileParse, Err: err}
+	}
+
+	return state, state.validate()
+}
+
+// Разбор желаемого состояния в формате YAML
+func ParseYAML(data []byte) (DesiredState, error) {
+	var state DesiredState
+	if err := yaml.Unmarshal(data, &state); err != nil {
+		return nil, &andromeda.Error{Code: andromeda.CodeFileParse, Err: err}
+	}
+
+	return state, state.validate()
+}
+
+// Проверка заполнения желаемого состояния
+func (s DesiredState) validate() error {
+	for siteId, customers := range s {
+		if siteId == "" {
+			return &andromeda.Error{Code: andromeda.CodeSiteIdRequired}
+		}
+		for custId, access := range customers {
+			if custId == "" {
+				return &andromeda.Error{Code: andromeda.CodeUserIdRequired, Detail: siteId}
+			}
+			if access.Role != RoleAdmin && access.Role != RoleUser && access.Role != RoleUnlink {
+				return &andromeda.Error{Code: andromeda.CodeRoleInvalid, Detail: siteId + " " + custId + ": " + access.Role}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Построение плана изменений: для каждого объекта из desired запрашивается текущий список пользователей MyAlarm
+func (r *Reconciler) Plan(ctx context.Context, desired DesiredState) (Plan, error) {
+	if err := desired.validate(); err != nil {
+		return Plan{}, err
+	}
+
+	siteIds := make([]string, 0, len(desired))
+	for siteId := range desired {
+		siteIds = append(siteIds, siteId)
+	}
+	sort.Strings(siteIds)
+
+	plan := Plan{Actions: []Action{}}
+	for _, siteId := range siteIds {
+		current, err := r.Client.GetUsersMyAlarm(ctx, andromeda.GetUsersMyAlarmInput{
+			SiteId:   siteId,
+			UserName: r.UserName,
+			Config:   r.Config,
+		})
+		if err != nil {
+			return Plan{}, &andromeda.Error{Code: andromeda.CodeMyAlarmUsersFetch, Detail: siteId, Err: err}
+		}
+		plan.Actions = append(plan.Actions, diffSite(siteId, desired[siteId], current, r.Prune)...)
+	}
+
+	return plan, nil
+}
+
+// Сравнение желаемых и текущих доступов одного объекта
+func diffSite(siteId string, desired map[string]Access, current []andromeda.UserMyAlarmResponse, prune bool) []Action {
+	byId := make(map[string]andromeda.UserMyAlarmResponse, len(current))
+	for _, u := range current {
+		byId[u.CustomerID] = u
+	}
+
+	custIds := make([]string, 0, len(desired))
+	for custId := range desired {
+		custIds = append(custIds, custId)
+	}
+	sort.Strings(custIds)
+
+	var actions []Action
+	for _, custId := range custIds {
+		want := desired[custId]
+		have, linked := byId[custId]
+		prevRole := RoleUnlink
+		if linked {
+			prevRole = have.Role
+		}
+
+		if want.Role != prevRole {
+			actions = append(actions, Action{
+				Type:     ActionChangeRole,
+				SiteId:   siteId,
+				CustId:   custId,
+				PrevRole: prevRole,
+				Role:     want.Role,
+			})
+		}
+
+		if want.Role != RoleUnlink && want.KTS != have.IsPanic {
+			actions = append(actions, Action{
+				Type:        ActionChangeKTS,
+				SiteId:      siteId,
+				CustId:      custId,
+				PrevIsPanic: have.IsPanic,
+				IsPanic:     want.KTS,
+			})
+		}
+	}
+
+	if prune {
+		for _, u := range current {
+			if _, ok := desired[u.CustomerID]; ok || u.Role == RoleUnlink {
+				continue
+			}
+			actions = append(actions, Action{
+				Type:     ActionChangeRole,
+				SiteId:   siteId,
+				CustId:   u.CustomerID,
+				PrevRole: u.Role,
+				Role:     RoleUnlink,
+			})
+		}
+	}
+
+	return actions
+}
@@ -0,0 +1,99 @@
+package reconcile
+
+import (
+	"context"
+	"testing"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		code andromeda.ErrorCode
+	}{
+		{name: "верное состояние", yaml: "s1:\n  c1: {role: admin, kts: true}\n  c2: {role: unlink}\n"},
+		{name: "неизвестная роль", yaml: "s1:\n  c1: {role: owner}\n", code: andromeda.CodeRoleInvalid},
+		{name: "пустой идентификатор пользователя", yaml: "s1:\n  \"\": {role: user}\n", code: andromeda.CodeUserIdRequired},
+		{name: "пустой идентификатор объекта", yaml: "\"\":\n  c1: {role: user}\n", code: andromeda.CodeSiteIdRequired},
+		{name: "неверный формат", yaml: "s1: [", code: andromeda.CodeFileParse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseYAML([]byte(tt.yaml))
+			if got := andromeda.CodeOf(err); got != tt.code {
+				t.Fatalf("код ошибки %q, ожидался %q (%v)", got, tt.code, err)
+			}
+		})
+	}
+}
+
+func TestPlan(t *testing.T) {
+	current := map[string][]andromeda.UserMyAlarmResponse{
+		"s1": {
+			{CustomerID: "c1", Role: RoleUser, IsPanic: false},
+			{CustomerID: "c2", Role: RoleAdmin, IsPanic: true},
+			{CustomerID: "c3", Role: RoleUser},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		desired DesiredState
+		prune   bool
+		want    []string
+		code    andromeda.ErrorCode
+	}{
+		{
+			name:    "без изменений",
+			desired: DesiredState{"s1": {"c1": {Role: RoleUser}, "c2": {Role: RoleAdmin, KTS: true}}},
+		},
+		{
+			name:    "смена роли и КТС",
+			desired: DesiredState{"s1": {"c1": {Role: RoleAdmin, KTS: true}}},
+			want:    []string{"пользователь c1: роль user -> admin", "пользователь c1: КТС запрещён -> разрешён"},
+		},
+		{
+			name:    "новый пользователь",
+			desired: DesiredState{"s1": {"c4": {Role: RoleUser}}},
+			want:    []string{"пользователь c4: роль unlink -> user"},
+		},
+		{
+			name:    "отвязка без изменения КТС",
+			desired: DesiredState{"s1": {"c2": {Role: RoleUnlink}}},
+			want:    []string{"пользователь c2: роль admin -> unlink"},
+		},
+		{
+			name:    "отвязка отсутствующих в файле",
+			desired: DesiredState{"s1": {"c1": {Role: RoleUser}}},
+			prune:   true,
+			want:    []string{"пользователь c2: роль admin -> unlink", "пользователь c3: роль user -> unlink"},
+		},
+		{
+			name:    "объект не найден",
+			desired: DesiredState{"s2": {"c1": {Role: RoleUser}}},
+			code:    andromeda.CodeMyAlarmUsersFetch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Reconciler{Client: &fakeAPI{users: current}, Prune: tt.prune}
+			plan, err := r.Plan(context.Background(), tt.desired)
+			if got := andromeda.CodeOf(err); got != tt.code {
+				t.Fatalf("код ошибки %q, ожидался %q (%v)", got, tt.code, err)
+			}
+
+			if len(plan.Actions) != len(tt.want) {
+				t.Fatalf("изменений %d, ожидалось %d: %v", len(plan.Actions), len(tt.want), plan.Actions)
+			}
+			for idx, a := range plan.Actions {
+				if a.String() != tt.want[idx] {
+					t.Errorf("изменение %d: %q, ожидалось %q", idx, a, tt.want[idx])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package andromeda
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	defaultMaxResponseSize  = 64 << 20 //Ограничение размера успешного ответа по умолчанию
+	defaultMaxErrorBodySize = 64 << 10 //Ограничение размера тела ответа с ошибкой по умолчанию
+	maxDrainSize            = 256 << 10
+	snippetSize             = 256
+)
+
+// Ошибка, полученная от сервера или связанная с форматом его ответа
+type APIError struct {
+	Code         ErrorCode //CodeServerError, CodeHTTPStatus или CodeUnexpectedContent
+	StatusCode   int       //HTTP статус ответа
+	Message      string    //Сообщение сервера (ответ 400 в формате JSON), выводится без перевода
+	SpResultCode int       //Код результата сервера (ответ 400 в формате JSON)
+	ContentType  string    //Тип содержимого ответа
+	Snippet      string    //Начало тела ответа, если он не в формате JSON (например HTML страница прокси)
+	Lang         Lang      //Язык сообщения
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+
+	msg := e.Code.Message(e.Lang)
+	if e.Code == CodeHTTPStatus {
+		msg += " " + strconv.Itoa(e.StatusCode)
+	}
+	if e.ContentType != "" {
+		msg += " (" + e.ContentType + ")"
+	}
+	if e.Snippet != "" {
+		msg += ": " + e.Snippet
+	}
+
+	return msg
+}
+
+// Максимальный размер тела ответа с ошибкой в байтах (по умолчанию 64 КБ)
+func WithMaxErrorBodySize(n int64) Option {
+	return func(c *Client) {
+		c.maxErrorBodySize = n
+	}
+}
+
+// Разбор ответа с ошибкой. Тело читается не больше maxErrorBodySize байт
+func (c *Client) responseError(resp *http.Response) error {
+	limit := c.maxErrorBodySize
+	if limit <= 0 {
+		limit = defaultMaxErrorBodySize
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, limit))
+	apiErr := &APIError{Code: CodeHTTPStatus, StatusCode: resp.StatusCode, ContentType: resp.Header.Get("Content-Type"), Lang: c.lang}
+
+	if resp.StatusCode == http.StatusBadRequest && looksLikeJSON(body) {
+		err400 := respErr400{}
+		if err := json.Unmarshal(body, &err400); err == nil && err400.Message != "" {
+			apiErr.Code = CodeServerError
+			apiErr.Message = err400.Message
+			apiErr.SpResultCode = err400.SpResultCode
+			return apiErr
+		}
+	}
+
+	apiErr.Snippet = snippet(body)
+	return apiErr
+}
+
+// Проверка формата успешного ответа. Ответ без JSON типа содержимого принимается, если тело пустое
+// или похоже на JSON; иначе возвращается APIError с началом тела
+func (c *Client) checkContentType(resp *http.Response) error {
+	ct := resp.Header.Get("Content-Type")
+	if media, _, err := mime.ParseMediaType(ct); err == nil && (media == "application/json" || media == "text/json" || strings.HasSuffix(media, "+json")) {
+		return nil
+	}
+
+	br := bufio.NewReader(resp.Body)
+	resp.Body = readCloser{Reader: br, Closer: resp.Body}
+
+	head, _ := br.Peek(snippetSize)
+	if looksLikeJSON(head) || len(bytes.TrimSpace(head)) == 0 {
+		return nil
+	}
+
+	return &APIError{Code: CodeUnexpectedContent, StatusCode: resp.StatusCode, ContentType: ct, Snippet: snippet(head), Lang: c.lang}
+}
+
+// Дочитывание и закрытие тела ответа, чтобы соединение могло быть использовано повторно
+func drainBody(body io.ReadCloser) {
+	io.Copy(io.Discard, io.LimitReader(body, maxDrainSize))
+	body.Close()
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// Признак того, что данные начинаются как JSON значение
+func looksLikeJSON(data []byte) bool {
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(data) == 0 {
+		return false
+	}
+	switch data[0] {
+	case '{', '[', '"', 't', 'f', 'n', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return true
+	}
+
+	return false
+}
+
+// Начало тела ответа для сообщения об ошибке: не больше snippetSize байт, пробелы схлопываются
+func snippet(body []byte) string {
+	if len(body) > snippetSize {
+		body = body[:snippetSize]
+	}
+	for len(body) > 0 && !utf8.Valid(body) {
+		body = body[:len(body)-1]
+	}
+
+	return strings.Join(strings.Fields(string(body)), " ")
+}
@@ -0,0 +1,131 @@
+package andromeda
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func rawHandler(status int, contentType, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}
+}
+
+func TestResponseErrors(t *testing.T) {
+	proxyPage := "<html>\n<body>  502 Bad   Gateway </body></html>"
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    *APIError //nil - ответ разобран без ошибки
+	}{
+		{name: "JSON ответ", handler: rawHandler(http.StatusOK, "application/json; charset=utf-8", `{"Id":"s1"}`)},
+		{name: "JSON без типа содержимого", handler: rawHandler(http.StatusOK, "text/plain", ` {"Id":"s1"}`)},
+		{
+			name:    "ошибка сервера в формате JSON",
+			handler: rawHandler(http.StatusBadRequest, "application/json", `{"Message":"объект не найден","SpResultCode":3}`),
+			want:    &APIError{Code: CodeServerError, StatusCode: 400, Message: "объект не найден", SpResultCode: 3, ContentType: "application/json"},
+		},
+		{
+			name:    "ответ 400 без JSON",
+			handler: rawHandler(http.StatusBadRequest, "text/plain", "bad request"),
+			want:    &APIError{Code: CodeHTTPStatus, StatusCode: 400, ContentType: "text/plain", Snippet: "bad request"},
+		},
+		{
+			name:    "страница прокси",
+			handler: rawHandler(http.StatusBadGateway, "text/html", proxyPage),
+			want:    &APIError{Code: CodeHTTPStatus, StatusCode: 502, ContentType: "text/html", Snippet: "<html> <body> 502 Bad Gateway </body></html>"},
+		},
+		{
+			name:    "HTML вместо JSON при статусе 200",
+			handler: rawHandler(http.StatusOK, "text/html", proxyPage),
+			want:    &APIError{Code: CodeUnexpectedContent, StatusCode: 200, ContentType: "text/html", Snippet: "<html> <body> 502 Bad Gateway </body></html>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newCountingServer(t, tt.handler)
+			site, err := NewClient().GetSites(context.Background(), GetSitesInput{Id: "1", Config: Config{Host: srv.URL, ApiKey: "key"}})
+
+			if tt.want == nil {
+				if err != nil || site.Id != "s1" {
+					t.Fatalf("объект %q, ошибка %v", site.Id, err)
+				}
+				return
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("ошибка %v, ожидалась APIError", err)
+			}
+			apiErr.Lang = ""
+			if *apiErr != *tt.want {
+				t.Fatalf("ошибка %+v, ожидалась %+v", *apiErr, *tt.want)
+			}
+			if CodeOf(err) != tt.want.Code {
+				t.Fatalf("код ошибки %q, ожидался %q", CodeOf(err), tt.want.Code)
+			}
+		})
+	}
+}
+
+func TestMaxErrorBodySize(t *testing.T) {
+	srv := newCountingServer(t, rawHandler(http.StatusInternalServerError, "text/plain", strings.Repeat("a", 100)+strings.Repeat("b", 100)))
+	_, err := NewClient(WithMaxErrorBodySize(100)).GetSites(context.Background(), GetSitesInput{Id: "1", Config: Config{Host: srv.URL, ApiKey: "key"}})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("ошибка %v, ожидалась APIError", err)
+	}
+	if apiErr.Snippet != strings.Repeat("a", 100) {
+		t.Fatalf("начало тела %q, ожидалось 100 символов a", apiErr.Snippet)
+	}
+}
+
+func TestAPIErrorMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *APIError
+		want string
+	}{
+		{name: "сообщение сервера", err: &APIError{Code: CodeServerError, Message: "объект не найден", Lang: LangEN}, want: "объект не найден"},
+		{name: "статус и начало тела", err: &APIError{Code: CodeHTTPStatus, StatusCode: 502, ContentType: "text/html", Snippet: "Bad Gateway", Lang: LangRU}, want: CodeHTTPStatus.Message(LangRU) + " 502 (text/html): Bad Gateway"},
+		{name: "неожиданный тип содержимого", err: &APIError{Code: CodeUnexpectedContent, StatusCode: 200, Lang: LangEN}, want: CodeUnexpectedContent.Message(LangEN)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Fatalf("сообщение %q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "пробелы схлопываются", body: " a \n\t b ", want: "a b"},
+		{name: "длинное тело обрезается", body: strings.Repeat("x", snippetSize+10), want: strings.Repeat("x", snippetSize)},
+		{name: "обрезанный символ UTF-8 отбрасывается", body: strings.Repeat("x", snippetSize-1) + "я", want: strings.Repeat("x", snippetSize-1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := snippet([]byte(tt.body)); got != tt.want {
+				t.Fatalf("%q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}
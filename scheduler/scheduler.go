@@ -0,0 +1,416 @@
+// Пакет scheduler отслеживает временно отключенные объекты и выполняет отключение и включение объектов по расписанию.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+const (
+	TaskDisable = "disable"
+	TaskEnable  = "enable"
+
+	defaultInterval = time.Minute
+	maxAttempts     = 5
+)
+
+// Ошибка при попытке изменить объект без заданного SiteUpdater
+var ErrNoUpdater = &andromeda.Error{Code: andromeda.CodeUpdaterRequired}
+
+type (
+	//Методы SDK для получения карточек объектов. Реализуется *andromeda.Client
+	API interface {
+		GetSites(ctx context.Context, input andromeda.GetSitesInput) (andromeda.GetSitesResponse, error)
+	}
+
+	//Изменение признака отключения объекта. SDK пока не содержит методов изменения карточки объекта,
+	//поэтому реализация передаётся вызывающей стороной
+	SiteUpdater interface {
+		//Отключить (disabled = true) или включить объект. autoEnableAt - дата автоматического включения, нулевое значение - без автовключения
+		SetSiteDisabled(ctx context.Context, siteId string, disabled bool, autoEnableAt time.Time) error
+	}
+
+	//Отключенный объект
+	Suspension struct {
+		SiteId        string    `json:"siteId"`
+		AccountNumber int       `json:"accountNumber"`
+		Name          string    `json:"name"`
+		DisabledAt    time.Time `json:"disabledAt"`   //Дата отключения (DisableDate)
+		AutoEnable    bool      `json:"autoEnable"`   //Объект будет включен автоматически
+		AutoEnableAt  time.Time `json:"autoEnableAt"` //Дата автоматического включения (AutoEnableDate)
+		ObservedAt    time.Time `json:"observedAt"`   //Когда отключение было обнаружено или выполнено
+	}
+
+	//Запланированное отключение или включение объекта
+	Task struct {
+		Id       string    `json:"id"`
+		SiteId   string    `json:"siteId"`
+		Action   string    `json:"action"` //TaskDisable или TaskEnable
+		At       time.Time `json:"at"`
+		Until    time.Time `json:"until"`          //Для TaskDisable: дата включения, передаётся серверу как AutoEnableDate
+		Pair     string    `json:"pair,omitempty"` //Для TaskDisable: Id задачи включения, отменяемой, если отключить объект не удалось
+		Attempts int       `json:"attempts"`
+		Error    string    `json:"error,omitempty"` //Последняя ошибка выполнения
+	}
+
+	//Сохраняемое состояние планировщика
+	State struct {
+		Suspensions map[string]Suspension `json:"suspensions"`
+		Tasks       []Task                `json:"tasks"`
+		NextTaskId  int                   `json:"nextTaskId"`
+	}
+
+	//Событие планировщика
+	Event struct {
+		Task Task
+		Err  error
+	}
+
+	//Параметры планировщика
+	Options struct {
+		Client   API              //Для Refresh
+		Config   andromeda.Config //Для Refresh
+		UserName string           //Имя пользователя, от которого делаются запросы (необязательное поле)
+		Updater  SiteUpdater      //Для Suspend и выполнения задач
+		Store    Store            //По умолчанию состояние хранится только в памяти
+		Clock    Clock            //По умолчанию SystemClock
+		OnEvent  func(Event)      //Вызывается после выполнения каждой задачи (необязательное поле)
+	}
+
+	//Планировщик отключений объектов
+	Scheduler struct {
+		opts    Options
+		mu      sync.Mutex
+		state   State
+		running map[string]bool //Id задач, выполняемых сейчас одним из вызовов Tick
+	}
+)
+
+// Создание планировщика и загрузка сохранённого состояния
+func New(opts Options) (*Scheduler, error) {
+	if opts.Store == nil {
+		opts.Store = &MemoryStore{}
+	}
+	if opts.Clock == nil {
+		opts.Clock = SystemClock
+	}
+
+	state, err := opts.Store.Load()
+	if err != nil {
+		return nil, err
+	}
+	if state.Suspensions == nil {
+		state.Suspensions = map[string]Suspension{}
+	}
+
+	return &Scheduler{opts: opts, state: state, running: map[string]bool{}}, nil
+}
+
+func (s State) clone() State {
+	c := State{Suspensions: make(map[string]Suspension, len(s.Suspensions)), NextTaskId: s.NextTaskId}
+	for k, v := range s.Suspensions {
+		c.Suspensions[k] = v
+	}
+	c.Tasks = append([]Task{}, s.Tasks...)
+	return c
+}
+
+// Учёт карточек объектов: отключенные объекты добавляются в список, включенные удаляются из него
+func (s *Scheduler) Observe(sites ...andromeda.GetSitesResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.opts.Clock.Now()
+	for _, site := range sites {
+		if !site.Disabled {
+			delete(s.state.Suspensions, site.Id)
+			continue
+		}
+
+		disabledAt, _ := andromeda.ParseDate(site.DisableDate)
+		sus := Suspension{
+			SiteId:        site.Id,
+			AccountNumber: site.AccountNumber,
+			Name:          site.Name,
+			DisabledAt:    disabledAt,
+			AutoEnable:    site.AutoEnable,
+			ObservedAt:    now,
+		}
+		if prev, ok := s.state.Suspensions[site.Id]; ok {
+			sus.ObservedAt = prev.ObservedAt
+		}
+		if site.AutoEnable {
+			sus.AutoEnableAt, _ = andromeda.ParseDate(site.AutoEnableDate)
+		}
+		s.state.Suspensions[site.Id] = sus
+	}
+
+	return s.opts.Store.Save(s.state.clone())
+}
+
+// Получение карточек объектов через SDK и их учёт
+func (s *Scheduler) Refresh(ctx context.Context, ids []string) error {
+	if s.opts.Client == nil {
+		return &andromeda.Error{Code: andromeda.CodeClientRequired}
+	}
+
+	results, _ := andromeda.Bulk(ctx, ids, func(ctx context.Context, id string) (andromeda.GetSitesResponse, error) {
+		return s.opts.Client.GetSites(ctx, andromeda.GetSitesInput{Id: id, UserName: s.opts.UserName, Config: s.opts.Config})
+	}, andromeda.BulkOptions{})
+
+	sites := make([]andromeda.GetSitesResponse, 0, len(results))
+	var firstErr error
+	for _, r := range results {
+		if r.Err != nil {
+			if firstErr == nil {
+				firstErr = &andromeda.Error{Code: andromeda.CodeSiteFetch, Detail: r.Input, Err: r.Err}
+			}
+			continue
+		}
+		sites = append(sites, r.Output)
+	}
+
+	if err := s.Observe(sites...); err != nil {
+		return err
+	}
+
+	return firstErr
+}
+
+// Список отключенных объектов, упорядоченный по дате отключения
+func (s *Scheduler) Suspended() []Suspension {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]Suspension, 0, len(s.state.Suspensions))
+	for _, sus := range s.state.Suspensions {
+		list = append(list, sus)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].DisabledAt.Before(list[j].DisabledAt) })
+
+	return list
+}
+
+// Объекты, которые будут автоматически включены в течение within, упорядоченные по дате включения
+func (s *Scheduler) UpcomingAutoEnables(within time.Duration) []Suspension {
+	horizon := s.opts.Clock.Now().Add(within)
+
+	var list []Suspension
+	for _, sus := range s.Suspended() {
+		if sus.AutoEnable && !sus.AutoEnableAt.IsZero() && !sus.AutoEnableAt.After(horizon) {
+			list = append(list, sus)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].AutoEnableAt.Before(list[j].AutoEnableAt) })
+
+	return list
+}
+
+// Запланированные задачи, упорядоченные по времени выполнения
+func (s *Scheduler) Tasks() []Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := append([]Task{}, s.state.Tasks...)
+	sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].At.Before(tasks[j].At) })
+
+	return tasks
+}
+
+// Отключение объекта на период: с from до until. Если from не позже текущего времени, объект отключается
+// при ближайшем вызове Tick. Включение объекта выполняется планировщиком в момент until
+func (s *Scheduler) Suspend(siteId string, from, until time.Time) error {
+	if s.opts.Updater == nil {
+		return ErrNoUpdater
+	}
+	if siteId == "" {
+		return &andromeda.Error{Code: andromeda.CodeSiteIdRequired}
+	}
+	if !until.After(from) {
+		return &andromeda.Error{Code: andromeda.CodePeriodInvalid}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cancelLocked(siteId)
+	s.addTaskLocked(Task{SiteId: siteId, Action: TaskDisable, At: from, Until: until})
+	disable := len(s.state.Tasks) - 1
+	enableId := s.addTaskLocked(Task{SiteId: siteId, Action: TaskEnable, At: until})
+	s.state.Tasks[disable].Pair = enableId
+
+	return s.opts.Store.Save(s.state.clone())
+}
+
+// Включение объекта в момент at
+func (s *Scheduler) ScheduleEnable(siteId string, at time.Time) error {
+	if s.opts.Updater == nil {
+		return ErrNoUpdater
+	}
+	if siteId == "" {
+		return &andromeda.Error{Code: andromeda.CodeSiteIdRequired}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cancelLocked(siteId)
+	s.addTaskLocked(Task{SiteId: siteId, Action: TaskEnable, At: at})
+
+	return s.opts.Store.Save(s.state.clone())
+}
+
+// Отмена всех запланированных задач объекта
+func (s *Scheduler) Cancel(siteId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cancelLocked(siteId)
+
+	return s.opts.Store.Save(s.state.clone())
+}
+
+func (s *Scheduler) cancelLocked(siteId string) {
+	tasks := s.state.Tasks[:0]
+	for _, t := range s.state.Tasks {
+		if t.SiteId != siteId {
+			tasks = append(tasks, t)
+		}
+	}
+	s.state.Tasks = tasks
+}
+
+func (s *Scheduler) addTaskLocked(t Task) string {
+	s.state.NextTaskId++
+	t.Id = strconv.Itoa(s.state.NextTaskId)
+	s.state.Tasks = append(s.state.Tasks, t)
+	return t.Id
+}
+
+func (s *Scheduler) removeTaskLocked(id string) {
+	for idx := range s.state.Tasks {
+		if s.state.Tasks[idx].Id == id {
+			s.state.Tasks = append(s.state.Tasks[:idx], s.state.Tasks[idx+1:]...)
+			return
+		}
+	}
+}
+
+// Выполнение задач, время которых наступило. Задача, завершившаяся ошибкой, повторяется при следующих вызовах
+// до maxAttempts раз, после чего удаляется; вместе с неудавшимся отключением удаляется и парная задача включения.
+// Задачи, уже выполняемые другим вызовом Tick, пропускаются. Возвращается первая ошибка
+func (s *Scheduler) Tick(ctx context.Context) error {
+	s.mu.Lock()
+	now := s.opts.Clock.Now()
+	var due []Task
+	for _, t := range s.state.Tasks {
+		if !t.At.After(now) && !s.running[t.Id] {
+			due = append(due, t)
+		}
+	}
+	if len(due) > 0 && s.opts.Updater == nil {
+		s.mu.Unlock()
+		return ErrNoUpdater
+	}
+	for _, t := range due {
+		s.running[t.Id] = true
+	}
+	s.mu.Unlock()
+
+	sort.SliceStable(due, func(i, j int) bool { return due[i].At.Before(due[j].At) })
+
+	var firstErr error
+	for _, t := range due {
+		var err error
+		if t.Action == TaskDisable {
+			err = s.opts.Updater.SetSiteDisabled(ctx, t.SiteId, true, t.Until)
+		} else {
+			err = s.opts.Updater.SetSiteDisabled(ctx, t.SiteId, false, time.Time{})
+		}
+
+		if saveErr := s.complete(t, err); saveErr != nil && firstErr == nil {
+			firstErr = saveErr
+		}
+		if err != nil && firstErr == nil {
+			firstErr = &andromeda.Error{Code: andromeda.CodeTaskFailed, Detail: t.Action + " " + t.SiteId, Err: err}
+		}
+
+		t.Attempts++
+		if err != nil {
+			t.Error = err.Error()
+		}
+		if s.opts.OnEvent != nil {
+			s.opts.OnEvent(Event{Task: t, Err: err})
+		}
+	}
+
+	return firstErr
+}
+
+// Учёт результата выполнения задачи
+func (s *Scheduler) complete(t Task, err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.running, t.Id)
+
+	now := s.opts.Clock.Now()
+	for idx := range s.state.Tasks {
+		if s.state.Tasks[idx].Id != t.Id {
+			continue
+		}
+		if err != nil && s.state.Tasks[idx].Attempts+1 < maxAttempts {
+			s.state.Tasks[idx].Attempts++
+			s.state.Tasks[idx].Error = err.Error()
+			break
+		}
+		s.state.Tasks = append(s.state.Tasks[:idx], s.state.Tasks[idx+1:]...)
+		// Объект так и не отключен: включать его в конце периода не нужно
+		if err != nil && t.Pair != "" {
+			s.removeTaskLocked(t.Pair)
+		}
+		break
+	}
+
+	if err == nil {
+		switch t.Action {
+		case TaskDisable:
+			s.state.Suspensions[t.SiteId] = Suspension{
+				SiteId:       t.SiteId,
+				DisabledAt:   now,
+				AutoEnable:   !t.Until.IsZero(),
+				AutoEnableAt: t.Until,
+				ObservedAt:   now,
+			}
+		case TaskEnable:
+			delete(s.state.Suspensions, t.SiteId)
+		}
+	}
+
+	return s.opts.Store.Save(s.state.clone())
+}
+
+// Периодическое выполнение Tick до отмены контекста. interval по умолчанию - одна минута
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	for {
+		if err := s.Tick(ctx); err != nil && errors.Is(err, ErrNoUpdater) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.opts.Clock.After(interval):
+		}
+	}
+}
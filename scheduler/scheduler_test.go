@@ -0,0 +1,367 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+var testNow = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+// Управляемые часы
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Интервал никогда не истекает: время переводится только через Add
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	return make(chan time.Time)
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Изменения объектов; fail - количество первых вызовов, завершающихся ошибкой.
+// Если задан block, вызов ждёт чтения из канала
+type fakeUpdater struct {
+	mu    sync.Mutex
+	calls []string
+	fail  int
+	block chan struct{}
+}
+
+func (u *fakeUpdater) SetSiteDisabled(ctx context.Context, siteId string, disabled bool, autoEnableAt time.Time) error {
+	if u.block != nil {
+		<-u.block
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.fail > 0 {
+		u.fail--
+		return errors.New("сервер недоступен")
+	}
+	if disabled {
+		u.calls = append(u.calls, "disable "+siteId+" "+autoEnableAt.Format(time.DateOnly))
+	} else {
+		u.calls = append(u.calls, "enable "+siteId)
+	}
+	return nil
+}
+
+func newTestScheduler(t *testing.T, updater SiteUpdater) (*Scheduler, *fakeClock) {
+	t.Helper()
+
+	clock := &fakeClock{now: testNow}
+	s, err := New(Options{Updater: updater, Clock: clock})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, clock
+}
+
+func TestObserve(t *testing.T) {
+	s, clock := newTestScheduler(t, nil)
+
+	err := s.Observe(
+		andromeda.GetSitesResponse{Id: "s1", Disabled: true, DisableDate: "2026-10-10T09:00:00", AutoEnable: true, AutoEnableDate: "2026-10-19"},
+		andromeda.GetSitesResponse{Id: "s2", Disabled: true, DisableDate: "01.10.2026"},
+		andromeda.GetSitesResponse{Id: "s3", Disabled: true, DisableDate: "2026-10-05", AutoEnable: true, AutoEnableDate: "2026-12-01"},
+		andromeda.GetSitesResponse{Id: "s4"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, sus := range s.Suspended() {
+		ids = append(ids, sus.SiteId)
+	}
+	if want := "s2 s3 s1"; strings.Join(ids, " ") != want {
+		t.Fatalf("отключенные объекты %v, ожидалось %s", ids, want)
+	}
+
+	upcoming := s.UpcomingAutoEnables(48 * time.Hour)
+	if len(upcoming) != 1 || upcoming[0].SiteId != "s1" {
+		t.Fatalf("ближайшие включения %+v, ожидался s1", upcoming)
+	}
+
+	// Повторное наблюдение сохраняет время обнаружения, включенный объект удаляется из списка
+	clock.Add(time.Hour)
+	if err := s.Observe(andromeda.GetSitesResponse{Id: "s1", Disabled: true}, andromeda.GetSitesResponse{Id: "s2"}); err != nil {
+		t.Fatal(err)
+	}
+	list := s.Suspended()
+	if len(list) != 2 {
+		t.Fatalf("отключенных объектов %d, ожидалось 2", len(list))
+	}
+	for _, sus := range list {
+		if !sus.ObservedAt.Equal(testNow) {
+			t.Errorf("объект %s: время обнаружения %s, ожидалось %s", sus.SiteId, sus.ObservedAt, testNow)
+		}
+	}
+}
+
+func TestSuspendValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		updater SiteUpdater
+		siteId  string
+		until   time.Time
+		code    andromeda.ErrorCode
+	}{
+		{name: "без SiteUpdater", siteId: "s1", until: testNow.Add(time.Hour), code: andromeda.CodeUpdaterRequired},
+		{name: "без идентификатора объекта", updater: &fakeUpdater{}, until: testNow.Add(time.Hour), code: andromeda.CodeSiteIdRequired},
+		{name: "окончание раньше начала", updater: &fakeUpdater{}, siteId: "s1", until: testNow.Add(-time.Hour), code: andromeda.CodePeriodInvalid},
+		{name: "верный период", updater: &fakeUpdater{}, siteId: "s1", until: testNow.Add(time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestScheduler(t, tt.updater)
+			err := s.Suspend(tt.siteId, testNow, tt.until)
+			if got := andromeda.CodeOf(err); got != tt.code {
+				t.Fatalf("код ошибки %q, ожидался %q (%v)", got, tt.code, err)
+			}
+		})
+	}
+}
+
+func TestTick(t *testing.T) {
+	updater := &fakeUpdater{}
+	s, clock := newTestScheduler(t, updater)
+	var events []Event
+	s.opts.OnEvent = func(e Event) { events = append(events, e) }
+
+	until := testNow.Add(48 * time.Hour)
+	if err := s.Suspend("s1", testNow.Add(time.Hour), until); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		advance   time.Duration
+		calls     int
+		suspended int
+		tasks     int
+	}{
+		{advance: 0, calls: 0, suspended: 0, tasks: 2},
+		{advance: time.Hour, calls: 1, suspended: 1, tasks: 1},
+		{advance: 47 * time.Hour, calls: 2, suspended: 0, tasks: 0},
+	}
+	for idx, step := range steps {
+		clock.Add(step.advance)
+		if err := s.Tick(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if len(updater.calls) != step.calls || len(s.Suspended()) != step.suspended || len(s.Tasks()) != step.tasks {
+			t.Fatalf("шаг %d: вызовы %v, отключено %d, задач %d", idx+1, updater.calls, len(s.Suspended()), len(s.Tasks()))
+		}
+	}
+
+	if updater.calls[0] != "disable s1 2026-10-20" || updater.calls[1] != "enable s1" {
+		t.Fatalf("вызовы %v", updater.calls)
+	}
+	if len(events) != 2 || events[0].Task.Action != TaskDisable || events[1].Task.Action != TaskEnable {
+		t.Fatalf("события %+v", events)
+	}
+}
+
+func TestTickRetry(t *testing.T) {
+	tests := []struct {
+		name  string
+		fail  int
+		tasks int //Задач после maxAttempts вызовов Tick
+		calls int
+	}{
+		{name: "успех после повторов", fail: maxAttempts - 1, calls: 1},
+		{name: "задача удаляется после maxAttempts ошибок", fail: maxAttempts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updater := &fakeUpdater{fail: tt.fail}
+			s, _ := newTestScheduler(t, updater)
+			if err := s.ScheduleEnable("s1", testNow); err != nil {
+				t.Fatal(err)
+			}
+
+			for n := range maxAttempts {
+				err := s.Tick(context.Background())
+				if n < tt.fail && andromeda.CodeOf(err) != andromeda.CodeTaskFailed {
+					t.Fatalf("попытка %d: ошибка %v, ожидался код %q", n+1, err, andromeda.CodeTaskFailed)
+				}
+			}
+			if len(s.Tasks()) != tt.tasks || len(updater.calls) != tt.calls {
+				t.Fatalf("задач %d, вызовов %d, ожидалось %d и %d", len(s.Tasks()), len(updater.calls), tt.tasks, tt.calls)
+			}
+		})
+	}
+}
+
+func TestTickDisableFailed(t *testing.T) {
+	updater := &fakeUpdater{fail: maxAttempts}
+	s, clock := newTestScheduler(t, updater)
+	if err := s.Suspend("s1", testNow, testNow.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	for range maxAttempts {
+		s.Tick(context.Background())
+	}
+	if tasks := s.Tasks(); len(tasks) != 0 {
+		t.Fatalf("задачи %+v, ожидалось удаление отключения вместе с включением", tasks)
+	}
+
+	// Объект не отключался, поэтому в конце периода включать его не нужно
+	clock.Add(time.Hour)
+	if err := s.Tick(context.Background()); err != nil || len(updater.calls) != 0 {
+		t.Fatalf("вызовы %v, ошибка %v", updater.calls, err)
+	}
+}
+
+func TestTickConcurrent(t *testing.T) {
+	updater := &fakeUpdater{block: make(chan struct{})}
+	s, _ := newTestScheduler(t, updater)
+	if err := s.ScheduleEnable("s1", testNow); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error)
+	go func() { done <- s.Tick(context.Background()) }()
+
+	// Пока первый Tick выполняет задачу, второй её пропускает
+	for {
+		s.mu.Lock()
+		claimed := s.running["1"]
+		s.mu.Unlock()
+		if claimed {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := s.Tick(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	close(updater.block)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if len(updater.calls) != 1 || len(s.Tasks()) != 0 {
+		t.Fatalf("вызовы %v, задач %d", updater.calls, len(s.Tasks()))
+	}
+}
+
+func TestCancel(t *testing.T) {
+	s, _ := newTestScheduler(t, &fakeUpdater{})
+	if err := s.Suspend("s1", testNow, testNow.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ScheduleEnable("s2", testNow); err != nil {
+		t.Fatal(err)
+	}
+	// Повторное планирование заменяет задачи объекта
+	if err := s.ScheduleEnable("s1", testNow.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(s.Tasks()); got != 2 {
+		t.Fatalf("задач %d, ожидалось 2", got)
+	}
+
+	if err := s.Cancel("s1"); err != nil {
+		t.Fatal(err)
+	}
+	tasks := s.Tasks()
+	if len(tasks) != 1 || tasks[0].SiteId != "s2" {
+		t.Fatalf("задачи %+v, ожидалась одна задача s2", tasks)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	store := FileStore{Path: filepath.Join(t.TempDir(), "scheduler.json")}
+	clock := &fakeClock{now: testNow}
+
+	s, err := New(Options{Updater: &fakeUpdater{}, Store: store, Clock: clock})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Suspend("s1", testNow.Add(time.Hour), testNow.Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Observe(andromeda.GetSitesResponse{Id: "s2", Disabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := New(Options{Updater: &fakeUpdater{}, Store: store, Clock: clock})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored.Tasks()) != 2 || len(restored.Suspended()) != 1 {
+		t.Fatalf("восстановлено задач %d, отключений %d", len(restored.Tasks()), len(restored.Suspended()))
+	}
+
+	// Номера задач продолжаются после перезапуска
+	if err := restored.ScheduleEnable("s3", testNow); err != nil {
+		t.Fatal(err)
+	}
+	ids := map[string]bool{}
+	for _, task := range restored.Tasks() {
+		if ids[task.Id] {
+			t.Fatalf("повторяющийся номер задачи %s", task.Id)
+		}
+		ids[task.Id] = true
+	}
+}
+
+func TestFileStoreErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name string
+		path string
+		code andromeda.ErrorCode
+	}{
+		{name: "файл отсутствует", path: filepath.Join(dir, "missing.json")},
+		{name: "каталог вместо файла", path: dir, code: andromeda.CodeFileRead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FileStore{Path: tt.path}.Load()
+			if got := andromeda.CodeOf(err); got != tt.code {
+				t.Fatalf("код ошибки %q, ожидался %q (%v)", got, tt.code, err)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s, _ := newTestScheduler(t, nil)
+	if err := s.ScheduleEnable("s1", testNow); !errors.Is(err, ErrNoUpdater) {
+		t.Fatalf("ошибка %v, ожидалась ErrNoUpdater", err)
+	}
+
+	s, _ = newTestScheduler(t, &fakeUpdater{})
+	if err := s.Run(ctx, 0); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ошибка %v, ожидалась context.Canceled", err)
+	}
+}
@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	andromeda "github.com/EkzikP/sdk-andromeda-go"
+)
+
+type (
+	//Источник текущего времени. В тестах подменяется управляемыми часами
+	Clock interface {
+		Now() time.Time
+		After(d time.Duration) <-chan time.Time
+	}
+
+	//Хранилище состояния планировщика между перезапусками
+	Store interface {
+		Load() (State, error)
+		Save(state State) error
+	}
+
+	//Хранение состояния в JSON файле
+	FileStore struct {
+		Path string
+	}
+
+	//Хранение состояния в памяти (без сохранения между перезапусками)
+	MemoryStore struct {
+		mu    sync.Mutex
+		state State
+	}
+
+	realClock struct{}
+)
+
+// Системные часы
+var SystemClock Clock = realClock{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Чтение состояния. Отсутствующий файл означает пустое состояние
+func (f FileStore) Load() (State, error) {
+	data, err := os.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, &andromeda.Error{Code: andromeda.CodeFileRead, Detail: f.Path, Err: err}
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return State{}, &andromeda.Error{Code: andromeda.CodeFileParse, Detail: f.Path, Err: err}
+	}
+
+	return state, nil
+}
+
+// Сохранение состояния через временный файл, чтобы не повредить его при сбое записи
+func (f FileStore) Save(state State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return &andromeda.Error{Code: andromeda.CodeFileWrite, Detail: f.Path, Err: err}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), filepath.Base(f.Path)+".*")
+	if err != nil {
+		return &andromeda.Error{Code: andromeda.CodeFileWrite, Detail: f.Path, Err: err}
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return &andromeda.Error{Code: andromeda.CodeFileWrite, Detail: f.Path, Err: err}
+	}
+	if err := tmp.Close(); err != nil {
+		return &andromeda.Error{Code: andromeda.CodeFileWrite, Detail: f.Path, Err: err}
+	}
+	if err := os.Rename(tmp.Name(), f.Path); err != nil {
+		return &andromeda.Error{Code: andromeda.CodeFileWrite, Detail: f.Path, Err: err}
+	}
+
+	return nil
+}
+
+func (m *MemoryStore) Load() (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state.clone(), nil
+}
+
+func (m *MemoryStore) Save(state State) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state = state.clone()
+	return nil
+}
@@ -0,0 +1,293 @@
+package andromeda
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	SchemaUnknownField = "unknown_field" //Поле ответа отсутствует в структуре SDK
+	SchemaMissingField = "missing_field" //Поле структуры SDK отсутствует в ответе
+	SchemaTypeMismatch = "type_mismatch" //Тип значения в ответе не соответствует типу поля структуры
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+type (
+	//Расхождение ответа сервера со структурой SDK
+	SchemaIssue struct {
+		Method   string //Метод SDK, например GetSites
+		Kind     string //SchemaUnknownField, SchemaMissingField или SchemaTypeMismatch
+		Path     string //Путь к полю, например «[].ObjCustName»
+		Expected string //Ожидаемый тип значения
+		Actual   string //Фактический тип значения
+	}
+
+	//Параметры строгого режима разбора ответов
+	StrictOptions struct {
+		Report func(issue SchemaIssue) //Вызывается для каждого расхождения
+		Fail   bool                    //Возвращать ошибку, если найдены расхождения (по умолчанию только сообщать)
+	}
+
+	//Ошибка строгого режима: ответ не соответствует структуре SDK
+	SchemaError struct {
+		Method string
+		Issues []SchemaIssue
+		Lang   Lang //Язык сообщения
+	}
+)
+
+// Строгий режим разбора ответов: расхождения ответов сервера со структурами SDK передаются в opts.Report
+func WithStrictDecoding(opts StrictOptions) Option {
+	return func(c *Client) {
+		c.strict = &opts
+	}
+}
+
+func (i SchemaIssue) String() string {
+	return i.Message(LangRU)
+}
+
+// Описание расхождения на языке lang
+func (i SchemaIssue) Message(lang Lang) string {
+	switch i.Kind {
+	case SchemaUnknownField:
+		return i.Method + ": " + CodeSchemaUnknown.Message(lang) + " " + i.Path + " (" + i.Actual + ")"
+	case SchemaMissingField:
+		return i.Method + ": " + CodeSchemaMissing.Message(lang) + " " + i.Path + " (" + i.Expected + ")"
+	}
+
+	return i.Method + ": " + i.Path + " (" + i.Actual + "): " + CodeSchemaType.Message(lang) + " " + i.Expected
+}
+
+func (e *SchemaError) Error() string {
+	msgs := make([]string, len(e.Issues))
+	for idx, issue := range e.Issues {
+		msgs[idx] = issue.Message(e.Lang)
+	}
+
+	return CodeSchemaMismatch.Message(e.Lang) + ": " + strings.Join(msgs, "; ")
+}
+
+// Разбор ответа метода с проверкой структуры в строгом режиме
+func (c *Client) decode(method string, body []byte, v any) error {
+	if c.strict != nil {
+		issues, err := DiffSchema(body, v)
+		if err == nil && len(issues) > 0 {
+			for idx := range issues {
+				issues[idx].Method = method
+				if c.strict.Report != nil {
+					c.strict.Report(issues[idx])
+				}
+			}
+			if c.strict.Fail {
+				return &SchemaError{Method: method, Issues: issues, Lang: c.lang}
+			}
+		}
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return c.error(CodeResponseDecode, err, "")
+	}
+
+	return nil
+}
+
+// Сравнение JSON ответа со структурой SDK. v - значение или указатель на значение ожидаемого типа.
+// Расхождения по элементам массивов объединяются (путь «[]»), результат упорядочен по пути
+func DiffSchema(data []byte, v any) ([]SchemaIssue, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var raw any
+	if err := dec.Decode(&raw); err != nil {
+		return nil, &Error{Code: CodeResponseDecode, Err: err}
+	}
+
+	typ := reflect.TypeOf(v)
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	seen := map[string]bool{}
+	var issues []SchemaIssue
+	add := func(issue SchemaIssue) {
+		key := issue.Kind + issue.Path
+		if !seen[key] {
+			seen[key] = true
+			issues = append(issues, issue)
+		}
+	}
+	diffValue(raw, typ, "", add)
+
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Path < issues[j].Path })
+
+	return issues, nil
+}
+
+func diffValue(raw any, typ reflect.Type, path string, add func(SchemaIssue)) {
+	if raw == nil {
+		return
+	}
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	// Типы с собственным разбором (например Money) не проверяются; структуры проверяются по полям
+	if typ.Kind() != reflect.Struct && reflect.PointerTo(typ).Implements(jsonUnmarshalerType) {
+		return
+	}
+
+	mismatch := func() {
+		add(SchemaIssue{Kind: SchemaTypeMismatch, Path: pathOrRoot(path), Expected: kindName(typ), Actual: jsonKind(raw)})
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		obj, ok := raw.(map[string]any)
+		if !ok {
+			mismatch()
+			return
+		}
+		diffStruct(obj, typ, path, add)
+	case reflect.Slice, reflect.Array:
+		arr, ok := raw.([]any)
+		if !ok {
+			mismatch()
+			return
+		}
+		for _, el := range arr {
+			diffValue(el, typ.Elem(), path+"[]", add)
+		}
+	case reflect.Map:
+		if _, ok := raw.(map[string]any); !ok {
+			mismatch()
+		}
+	case reflect.String:
+		if _, ok := raw.(string); !ok {
+			mismatch()
+		}
+	case reflect.Bool:
+		if _, ok := raw.(bool); !ok {
+			mismatch()
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := raw.(json.Number)
+		if !ok {
+			mismatch()
+			return
+		}
+		if _, err := n.Int64(); err != nil {
+			mismatch()
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := raw.(json.Number); !ok {
+			mismatch()
+		}
+	}
+}
+
+func diffStruct(obj map[string]any, typ reflect.Type, path string, add func(SchemaIssue)) {
+	fields := jsonFields(typ)
+
+	matched := map[string]bool{}
+	for key, val := range obj {
+		name, ok := matchField(fields, key)
+		if !ok {
+			add(SchemaIssue{Kind: SchemaUnknownField, Path: path + "." + key, Actual: jsonKind(val)})
+			continue
+		}
+		matched[name] = true
+		diffValue(val, fields[name], path+"."+name, add)
+	}
+
+	for name, ft := range fields {
+		if !matched[name] {
+			add(SchemaIssue{Kind: SchemaMissingField, Path: path + "." + name, Expected: kindName(ft)})
+		}
+	}
+}
+
+// Поля структуры по имени в JSON, включая поля встроенных структур
+func jsonFields(typ reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for idx := 0; idx < typ.NumField(); idx++ {
+		f := typ.Field(idx)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			for k, v := range jsonFields(f.Type) {
+				fields[k] = v
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+
+	return fields
+}
+
+// Поиск поля так же, как это делает encoding/json: сначала точное совпадение, затем без учёта регистра
+func matchField(fields map[string]reflect.Type, key string) (string, bool) {
+	if _, ok := fields[key]; ok {
+		return key, true
+	}
+	for name := range fields {
+		if strings.EqualFold(name, key) {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "."
+	}
+	return path
+}
+
+func jsonKind(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case json.Number:
+		return "number"
+	case []any:
+		return "array"
+	}
+
+	return "object"
+}
+
+func kindName(typ reflect.Type) string {
+	switch typ.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Float32, reflect.Float64, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "number"
+	}
+
+	return typ.Kind().String()
+}
@@ -0,0 +1,145 @@
+package andromeda
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStrictDecoding(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		strict *StrictOptions //nil - строгий режим не включён
+		issues []string
+	}{
+		{name: "ответ совпадает со структурой", body: `{"Status":1,"Description":"ok"}`, strict: &StrictOptions{}},
+		{
+			name:   "расхождения только передаются в Report",
+			body:   `{"Status":1,"Extra":true}`,
+			strict: &StrictOptions{},
+			issues: []string{"missing_field .Description", "unknown_field .Extra"},
+		},
+		{
+			name:   "Fail возвращает SchemaError",
+			body:   `{"Status":1,"Description":"ok","Extra":true}`,
+			strict: &StrictOptions{Fail: true},
+			issues: []string{"unknown_field .Extra"},
+		},
+		{name: "без строгого режима лишние поля игнорируются", body: `{"Status":1,"Extra":true}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newCountingServer(t, jsonHandler(tt.body))
+
+			var issues []string
+			var opts []Option
+			if tt.strict != nil {
+				tt.strict.Report = func(issue SchemaIssue) {
+					if issue.Method != "GetCheckPanic" {
+						t.Errorf("метод %q, ожидался GetCheckPanic", issue.Method)
+					}
+					issues = append(issues, issue.Kind+" "+issue.Path)
+				}
+				opts = append(opts, WithStrictDecoding(*tt.strict))
+			}
+
+			resp, err := NewClient(opts...).GetCheckPanic(context.Background(), GetCheckPanicInput{CheckPanicId: "c1", Config: Config{Host: srv.URL, ApiKey: "key"}})
+
+			if tt.strict != nil && tt.strict.Fail {
+				var schemaErr *SchemaError
+				if !errors.As(err, &schemaErr) || schemaErr.Method != "GetCheckPanic" || len(schemaErr.Issues) != len(tt.issues) {
+					t.Fatalf("ошибка %v, ожидалась SchemaError", err)
+				}
+			} else if err != nil || resp.Status != 1 {
+				t.Fatalf("ответ %+v, ошибка %v", resp, err)
+			}
+
+			if len(issues) != len(tt.issues) {
+				t.Fatalf("расхождения %v, ожидалось %v", issues, tt.issues)
+			}
+			for idx := range tt.issues {
+				if issues[idx] != tt.issues[idx] {
+					t.Errorf("расхождение %d: %q, ожидалось %q", idx, issues[idx], tt.issues[idx])
+				}
+			}
+		})
+	}
+}
+
+func TestDiffSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		v      any
+		issues []string
+	}{
+		{name: "тип поля", body: `{"Status":"1","Description":"ok"}`, v: GetCheckPanicResponse{}, issues: []string{"type_mismatch .Status"}},
+		{name: "элементы массива объединяются", body: `[{"Status":1,"Description":"a","X":1},{"Status":2,"Description":"b","X":2}]`, v: []GetCheckPanicResponse{}, issues: []string{"unknown_field [].X"}},
+		{name: "null не считается расхождением", body: `{"Status":null,"Description":null}`, v: &GetCheckPanicResponse{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues, err := DiffSchema([]byte(tt.body), tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(issues) != len(tt.issues) {
+				t.Fatalf("расхождения %+v, ожидалось %v", issues, tt.issues)
+			}
+			for idx, issue := range issues {
+				if got := issue.Kind + " " + issue.Path; got != tt.issues[idx] {
+					t.Errorf("расхождение %d: %q, ожидалось %q", idx, got, tt.issues[idx])
+				}
+			}
+		})
+	}
+
+	if _, err := DiffSchema([]byte(`{`), GetCheckPanicResponse{}); err == nil {
+		t.Fatal("ожидалась ошибка разбора неверного JSON")
+	}
+}
+
+func TestSchemaIssueMessage(t *testing.T) {
+	tests := []struct {
+		name  string
+		issue SchemaIssue
+		lang  Lang
+		want  string
+	}{
+		{
+			name:  "неизвестное поле",
+			issue: SchemaIssue{Method: "GetSites", Kind: SchemaUnknownField, Path: ".New", Actual: "number"},
+			lang:  LangRU,
+			want:  "GetSites: неизвестное поле .New (number)",
+		},
+		{
+			name:  "отсутствующее поле",
+			issue: SchemaIssue{Method: "GetSites", Kind: SchemaMissingField, Path: ".Name", Expected: "string"},
+			lang:  LangEN,
+			want:  "GetSites: missing field .Name (string)",
+		},
+		{
+			name:  "неверный тип",
+			issue: SchemaIssue{Method: "GetCheckPanic", Kind: SchemaTypeMismatch, Path: ".Status", Actual: "string", Expected: "number"},
+			lang:  LangRU,
+			want:  "GetCheckPanic: .Status (string): неверный тип поля, ожидался number",
+		},
+		{
+			name:  "неверный тип на английском",
+			issue: SchemaIssue{Method: "GetCheckPanic", Kind: SchemaTypeMismatch, Path: ".Status", Actual: "string", Expected: "number"},
+			lang:  LangEN,
+			want:  "GetCheckPanic: .Status (string): wrong field type, expected number",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.issue.Message(tt.lang); got != tt.want {
+				t.Fatalf("%q, ожидалось %q", got, tt.want)
+			}
+		})
+	}
+}
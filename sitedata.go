@@ -0,0 +1,53 @@
+package andromeda
+
+import "context"
+
+type (
+	//Методы SDK, которые использует FetchSiteData. Реализуется *Client
+	SiteDataAPI interface {
+		GetSites(ctx context.Context, input GetSitesInput) (GetSitesResponse, error)
+		Customers(ctx context.Context, input GetCustomersInput) ([]GetCustomerResponse, error)
+		GetParts(ctx context.Context, input GetPartsInput) ([]GetPartsResponse, error)
+		GetZones(ctx context.Context, input GetZonesInput) ([]GetZonesResponse, error)
+		GetUsersMyAlarm(ctx context.Context, input GetUsersMyAlarmInput) ([]UserMyAlarmResponse, error)
+	}
+
+	//Все данные объекта: карточка, ответственные, разделы, шлейфы и пользователи MyAlarm
+	SiteData struct {
+		Site      GetSitesResponse
+		Customers []GetCustomerResponse
+		Parts     []GetPartsResponse
+		Zones     []GetZonesResponse
+		Users     []UserMyAlarmResponse
+	}
+)
+
+// Загрузка всех данных объекта по номеру или идентификатору input.Id. Ошибка загрузки списков
+// возвращается с кодом списка (CodeCustomersFetch и т.д.) и идентификатором объекта
+func FetchSiteData(ctx context.Context, client SiteDataAPI, input GetSitesInput) (SiteData, error) {
+	var (
+		data SiteData
+		err  error
+	)
+
+	data.Site, err = client.GetSites(ctx, input)
+	if err != nil {
+		return data, err
+	}
+
+	siteId, user, cfg := data.Site.Id, input.UserName, input.Config
+	if data.Customers, err = client.Customers(ctx, GetCustomersInput{SiteId: siteId, UserName: user, Config: cfg}); err != nil {
+		return data, &Error{Code: CodeCustomersFetch, Detail: siteId, Err: err}
+	}
+	if data.Parts, err = client.GetParts(ctx, GetPartsInput{SiteId: siteId, UserName: user, Config: cfg}); err != nil {
+		return data, &Error{Code: CodePartsFetch, Detail: siteId, Err: err}
+	}
+	if data.Zones, err = client.GetZones(ctx, GetZonesInput{SiteId: siteId, UserName: user, Config: cfg}); err != nil {
+		return data, &Error{Code: CodeZonesFetch, Detail: siteId, Err: err}
+	}
+	if data.Users, err = client.GetUsersMyAlarm(ctx, GetUsersMyAlarmInput{SiteId: siteId, UserName: user, Config: cfg}); err != nil {
+		return data, &Error{Code: CodeMyAlarmUsersFetch, Detail: siteId, Err: err}
+	}
+
+	return data, nil
+}
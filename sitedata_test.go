@@ -0,0 +1,84 @@
+package andromeda
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// Данные одного объекта; fail - метод, который возвращает ошибку
+type fakeSiteData struct {
+	fail string
+}
+
+var errFake = errors.New("fake")
+
+func (f fakeSiteData) err(method string) error {
+	if f.fail == method {
+		return errFake
+	}
+	return nil
+}
+
+func (f fakeSiteData) GetSites(ctx context.Context, in GetSitesInput) (GetSitesResponse, error) {
+	return GetSitesResponse{Id: "guid-" + in.Id}, f.err("GetSites")
+}
+
+func (f fakeSiteData) Customers(ctx context.Context, in GetCustomersInput) ([]GetCustomerResponse, error) {
+	return []GetCustomerResponse{{Id: "c1"}}, f.err("Customers")
+}
+
+func (f fakeSiteData) GetParts(ctx context.Context, in GetPartsInput) ([]GetPartsResponse, error) {
+	return []GetPartsResponse{{Id: "p1"}}, f.err("GetParts")
+}
+
+func (f fakeSiteData) GetZones(ctx context.Context, in GetZonesInput) ([]GetZonesResponse, error) {
+	return []GetZonesResponse{{Id: "z1"}}, f.err("GetZones")
+}
+
+func (f fakeSiteData) GetUsersMyAlarm(ctx context.Context, in GetUsersMyAlarmInput) ([]UserMyAlarmResponse, error) {
+	if in.SiteId != "guid-1" {
+		return nil, errors.New("неверный идентификатор объекта")
+	}
+	return []UserMyAlarmResponse{{CustomerID: "c1"}}, f.err("GetUsersMyAlarm")
+}
+
+func TestFetchSiteData(t *testing.T) {
+	tests := []struct {
+		fail string
+		code ErrorCode
+	}{
+		{fail: ""},
+		{fail: "GetSites"},
+		{fail: "Customers", code: CodeCustomersFetch},
+		{fail: "GetParts", code: CodePartsFetch},
+		{fail: "GetZones", code: CodeZonesFetch},
+		{fail: "GetUsersMyAlarm", code: CodeMyAlarmUsersFetch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fail, func(t *testing.T) {
+			data, err := FetchSiteData(context.Background(), fakeSiteData{fail: tt.fail}, GetSitesInput{Id: "1"})
+			if tt.fail == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if data.Site.Id != "guid-1" || len(data.Customers) != 1 || len(data.Parts) != 1 || len(data.Zones) != 1 || len(data.Users) != 1 {
+					t.Fatalf("данные загружены не полностью: %+v", data)
+				}
+				return
+			}
+
+			if !errors.Is(err, errFake) {
+				t.Fatalf("ошибка %v, ожидалась вложенная errFake", err)
+			}
+			if got := CodeOf(err); got != tt.code {
+				t.Fatalf("код ошибки %q, ожидался %q", got, tt.code)
+			}
+			var e *Error
+			if tt.code != "" && (!errors.As(err, &e) || e.Detail != "guid-1") {
+				t.Fatalf("в ошибке нет идентификатора объекта: %v", err)
+			}
+		})
+	}
+}
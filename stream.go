@@ -0,0 +1,140 @@
+package andromeda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Ошибка при превышении максимального размера ответа
+var ErrResponseTooLarge = &Error{Code: CodeResponseTooLarge}
+
+// Максимальный размер тела успешного ответа в байтах (по умолчанию 64 МБ), 0 - без ограничения
+func WithMaxResponseSize(n int64) Option {
+	return func(c *Client) {
+		c.maxResponseSize = n
+	}
+}
+
+// Чтение тела ответа с ограничением размера
+type limitedReader struct {
+	r    io.Reader
+	n    int64 //Сколько байт ещё можно прочитать
+	lang Lang
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		// Лимит исчерпан: ответ допустим, только если данных больше нет
+		var one [1]byte
+		for {
+			n, err := l.r.Read(one[:])
+			if n > 0 {
+				return 0, &Error{Code: CodeResponseTooLarge, Lang: l.lang}
+			}
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+
+	return n, err
+}
+
+// Тело ответа с ограничением размера, если оно задано
+func (c *Client) limitBody(body io.Reader) io.Reader {
+	if c.maxResponseSize <= 0 {
+		return body
+	}
+	return &limitedReader{r: body, n: c.maxResponseSize, lang: c.lang}
+}
+
+// Потоковое выполнение метода, возвращающего массив: элементы разбираются по одному
+// и передаются в fn без чтения всего ответа в память. Если fn возвращает ошибку, чтение прекращается.
+// Строгий режим разбора (WithStrictDecoding) к потоковым методам не применяется
+func stream[In input, El any](ctx context.Context, c *Client, e endpoint[In, []El], in In, fn func(El) error) error {
+	if err := validateInput(in, c.credentials == nil, c.lang); err != nil {
+		return err
+	}
+
+	req, err := e.generateRequest(in, c.lang)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.send(ctx, e.method, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(c.limitBody(resp.Body))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return c.decodeError(err)
+	}
+	if tok == nil {
+		return nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return c.error(CodeResponseNotArray, nil, fmt.Sprint(tok))
+	}
+
+	for dec.More() {
+		var el El
+		if err := dec.Decode(&el); err != nil {
+			return c.decodeError(err)
+		}
+		if err := fn(el); err != nil {
+			return err
+		}
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return c.decodeError(err)
+	}
+
+	return nil
+}
+
+// Ошибка разбора ответа. Превышение размера ответа возвращается без обёртки
+func (c *Client) decodeError(err error) error {
+	if errors.Is(err, ErrResponseTooLarge) {
+		return err
+	}
+
+	return c.error(CodeResponseDecode, err, "")
+}
+
+// Потоковый запрос метода GetCustomers
+func (c *Client) StreamCustomers(ctx context.Context, input GetCustomersInput, fn func(GetCustomerResponse) error) error {
+	return stream(ctx, c, endpointGetCustomersDesc, input, fn)
+}
+
+// Потоковый запрос метода GetUsersMyAlarm
+func (c *Client) StreamUsersMyAlarm(ctx context.Context, input GetUsersMyAlarmInput, fn func(UserMyAlarmResponse) error) error {
+	return stream(ctx, c, endpointGetUsersMyAlarmDesc, input, fn)
+}
+
+// Потоковый запрос метода GetUserObjectMyAlarm
+func (c *Client) StreamUserObjectMyAlarm(ctx context.Context, input GetUserObjectMyAlarmInput, fn func(GetUserObjectMyAlarmResponse) error) error {
+	return stream(ctx, c, endpointGetUserObjectMyAlarmDesc, input, fn)
+}
+
+// Потоковый запрос метода GetParts
+func (c *Client) StreamParts(ctx context.Context, input GetPartsInput, fn func(GetPartsResponse) error) error {
+	return stream(ctx, c, endpointGetPartsDesc, input, fn)
+}
+
+// Потоковый запрос метода GetZones
+func (c *Client) StreamZones(ctx context.Context, input GetZonesInput, fn func(GetZonesResponse) error) error {
+	return stream(ctx, c, endpointGetZonesDesc, input, fn)
+}
@@ -0,0 +1,104 @@
+package andromeda
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStreamCustomers(t *testing.T) {
+	const list = `[{"Id":"c1"},{"Id":"c2"},{"Id":"c3"}]`
+	errStop := errors.New("остановлено")
+
+	tests := []struct {
+		name    string
+		body    string
+		maxSize int64
+		stopAt  string //Ответственный, на котором fn возвращает ошибку
+		want    []string
+		code    ErrorCode
+		err     error
+	}{
+		{name: "массив", body: list, want: []string{"c1", "c2", "c3"}},
+		{name: "пустой массив", body: `[]`},
+		{name: "null", body: `null`},
+		{name: "ответ точно по размеру", body: list, maxSize: int64(len(list)), want: []string{"c1", "c2", "c3"}},
+		{name: "ответ больше размера", body: list, maxSize: int64(len(list)) - 1, want: []string{"c1", "c2", "c3"}, code: CodeResponseTooLarge},
+		{name: "не массив", body: `{"Id":"c1"}`, code: CodeResponseNotArray},
+		{name: "обрезанный ответ", body: `[{"Id":"c1"},{"Id":`, want: []string{"c1"}, code: CodeResponseDecode},
+		{name: "остановка обработчиком", body: list, stopAt: "c2", want: []string{"c1", "c2"}, err: errStop},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newCountingServer(t, jsonHandler(tt.body))
+			c := NewClient(WithMaxResponseSize(tt.maxSize))
+
+			var got []string
+			err := c.StreamCustomers(context.Background(), GetCustomersInput{SiteId: "s1", Config: Config{Host: srv.URL, ApiKey: "key"}}, func(cust GetCustomerResponse) error {
+				got = append(got, cust.Id)
+				if cust.Id == tt.stopAt {
+					return errStop
+				}
+				return nil
+			})
+
+			switch {
+			case tt.err != nil:
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("ошибка %v, ожидалась %v", err, tt.err)
+				}
+			case CodeOf(err) != tt.code:
+				t.Fatalf("код ошибки %q, ожидался %q (%v)", CodeOf(err), tt.code, err)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("ответственные %v, ожидалось %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamValidation(t *testing.T) {
+	srv := newCountingServer(t, jsonHandler(`[]`))
+	err := NewClient().StreamZones(context.Background(), GetZonesInput{Config: Config{Host: srv.URL, ApiKey: "key"}}, func(GetZonesResponse) error {
+		return nil
+	})
+
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Field("SiteId") == nil {
+		t.Fatalf("ошибка %v, ожидалась ошибка проверки поля SiteId", err)
+	}
+	if srv.hits.Load() != 0 {
+		t.Fatal("запрос с незаполненными полями отправлен на сервер")
+	}
+}
+
+func TestLimitedReader(t *testing.T) {
+	tests := []struct {
+		body  string
+		limit int64
+		err   bool
+	}{
+		{body: "12345", limit: 5},
+		{body: "12345", limit: 10},
+		{body: "123456", limit: 5, err: true},
+		{body: "", limit: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			data, err := io.ReadAll(&limitedReader{r: strings.NewReader(tt.body), n: tt.limit})
+			if tt.err {
+				if !errors.Is(err, ErrResponseTooLarge) {
+					t.Fatalf("ошибка %v, ожидалась ErrResponseTooLarge", err)
+				}
+				return
+			}
+			if err != nil || string(data) != tt.body {
+				t.Fatalf("прочитано %q (%v), ожидалось %q", data, err, tt.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package andromeda
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Сертификат сервера не совпал ни с одним из закреплённых ключей
+var ErrPinMismatch = &Error{Code: CodeTLSPinMismatch}
+
+type (
+	//Параметры TLS соединения с сервером Андромеды
+	TLSOptions struct {
+		CAFile string //Файл с корневыми сертификатами (PEM), которые добавляются к системным
+		CAPEM  []byte //Корневые сертификаты (PEM), альтернатива CAFile
+		OnlyCA bool   //Доверять только CAFile/CAPEM, без системных корневых сертификатов
+
+		CertFile string //Сертификат клиента (PEM) для взаимной аутентификации
+		KeyFile  string //Закрытый ключ клиента (PEM)
+
+		MinVersion uint16 //Минимальная версия TLS, например tls.VersionTLS12 (по умолчанию TLS 1.2)
+
+		//Закреплённые открытые ключи: base64 от SHA-256 SubjectPublicKeyInfo (как в HPKP, с префиксом «sha256/» или без).
+		//Соединение разрешено, если ключ любого сертификата проверенной цепочки есть в списке.
+		//При InsecureSkipVerify цепочка не проверяется, и с закреплённым ключом сравнивается только сертификат сервера
+		PinnedSPKI []string
+
+		ServerName         string //Имя сервера для проверки сертификата, если отличается от адреса
+		InsecureSkipVerify bool   //Не проверять цепочку сертификата сервера (только для отладки)
+
+		Lang Lang //Язык сообщений об ошибках Config (по умолчанию русский)
+	}
+
+	//Ошибка установки TLS соединения
+	TLSError struct {
+		Code   ErrorCode //Код причины, например CodeTLSUnknownAuthority
+		Host   string
+		Reason string //Причина на языке клиента
+		Err    error
+		Lang   Lang
+	}
+)
+
+func (e *TLSError) Error() string {
+	msg := CodeTLS.Message(e.Lang)
+	if e.Host != "" {
+		msg += ": " + e.Host
+	}
+	return msg + ": " + e.Reason + ": " + e.Err.Error()
+}
+
+func (e *TLSError) Unwrap() error {
+	return e.Err
+}
+
+// Построение tls.Config по параметрам. Ошибки чтения сертификатов возвращаются сразу, а не при первом запросе
+func (o TLSOptions) Config() (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         o.MinVersion,
+		ServerName:         o.ServerName,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+	if cfg.MinVersion == 0 {
+		cfg.MinVersion = tls.VersionTLS12
+	}
+
+	if o.CAFile != "" || len(o.CAPEM) > 0 {
+		pool := x509.NewCertPool()
+		if !o.OnlyCA {
+			if sys, err := x509.SystemCertPool(); err == nil {
+				pool = sys
+			}
+		}
+		pem := o.CAPEM
+		if o.CAFile != "" {
+			data, err := os.ReadFile(o.CAFile)
+			if err != nil {
+				return nil, &Error{Code: CodeTLSCARead, Detail: o.CAFile, Err: err, Lang: o.Lang}
+			}
+			pem = append(append([]byte{}, pem...), data...)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, &Error{Code: CodeTLSCAEmpty, Lang: o.Lang}
+		}
+		cfg.RootCAs = pool
+	}
+
+	if o.CertFile != "" || o.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, &Error{Code: CodeTLSClientCert, Err: err, Lang: o.Lang}
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(o.PinnedSPKI) > 0 {
+		pins := make(map[string]bool, len(o.PinnedSPKI))
+		for _, pin := range o.PinnedSPKI {
+			pin = strings.TrimPrefix(strings.TrimSpace(pin), "sha256/")
+			raw, err := base64.StdEncoding.DecodeString(pin)
+			if err != nil || len(raw) != sha256.Size {
+				return nil, &Error{Code: CodeTLSPinInvalid, Detail: pin, Lang: o.Lang}
+			}
+			pins[pin] = true
+		}
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			if pinned(pins, cs) {
+				return nil
+			}
+			return ErrPinMismatch
+		}
+	}
+
+	return cfg, nil
+}
+
+// Проверка закреплённых ключей. Сертификаты, присланные сервером сверх проверенной цепочки, не учитываются:
+// иначе любой сервер с доверенным сертификатом мог бы дописать в цепочку закреплённый сертификат
+func pinned(pins map[string]bool, cs tls.ConnectionState) bool {
+	if len(cs.VerifiedChains) == 0 {
+		// Цепочка не проверялась (InsecureSkipVerify): ключ сервера должен быть закреплён сам
+		return len(cs.PeerCertificates) > 0 && pins[SPKIPin(cs.PeerCertificates[0])]
+	}
+
+	for _, chain := range cs.VerifiedChains {
+		for _, cert := range chain {
+			if pins[SPKIPin(cert)] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Закрепляемое значение открытого ключа сертификата: base64 от SHA-256 SubjectPublicKeyInfo
+func SPKIPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Параметры TLS соединения. Транспорт клиента заменяется копией http.DefaultTransport с заданным cfg
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(c *Client) {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = cfg
+		c.client.Transport = transport
+	}
+}
+
+// Перевод ошибки установки TLS соединения в TLSError с понятной причиной. Прочие ошибки возвращаются как есть
+func tlsError(err error, lang Lang) error {
+	var (
+		urlErr     *url.Error
+		unknownCA  x509.UnknownAuthorityError
+		hostErr    x509.HostnameError
+		invalidErr x509.CertificateInvalidError
+		verifyErr  *tls.CertificateVerificationError
+		alertErr   tls.AlertError
+		recordErr  tls.RecordHeaderError
+		code       ErrorCode
+	)
+
+	switch {
+	case errors.Is(err, ErrPinMismatch):
+		code = CodeTLSPinMismatch
+	case errors.As(err, &unknownCA):
+		code = CodeTLSUnknownAuthority
+	case errors.As(err, &hostErr):
+		code = CodeTLSHostname
+	case errors.As(err, &invalidErr):
+		if invalidErr.Reason == x509.Expired {
+			code = CodeTLSExpired
+		} else {
+			code = CodeTLSInvalid
+		}
+	case errors.As(err, &verifyErr):
+		code = CodeTLSVerifyFailed
+	case errors.As(err, &alertErr):
+		code = CodeTLSRejected
+	case errors.As(err, &recordErr):
+		code = CodeTLSNotTLS
+	default:
+		return err
+	}
+
+	host := ""
+	if errors.As(err, &urlErr) {
+		if u, perr := url.Parse(urlErr.URL); perr == nil {
+			host = u.Host
+		}
+	}
+
+	return &TLSError{Code: code, Host: host, Reason: code.Message(lang), Err: err, Lang: lang}
+}
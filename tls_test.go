@@ -0,0 +1,170 @@
+package andromeda
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// Сертификат для 127.0.0.1, подписанный parent, или самоподписанный, если parent == nil
+func newTestCert(t *testing.T, name string, isCA bool, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if !isCA {
+		tmpl.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+	}
+
+	signer, signerKey := tmpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testCert{cert: cert, key: key}
+}
+
+func (c *testCert) pem() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})
+}
+
+// TLS сервер, отдающий цепочку chain; первый сертификат - сертификат сервера
+func newTLSServer(t *testing.T, chain ...*testCert) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"Id":"1"}`))
+	}))
+	leaf := tls.Certificate{PrivateKey: chain[0].key}
+	for _, c := range chain {
+		leaf.Certificate = append(leaf.Certificate, c.cert.Raw)
+	}
+	srv.TLS = &tls.Config{Certificates: []tls.Certificate{leaf}}
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestTLSOptionsPinning(t *testing.T) {
+	ca := newTestCert(t, "ca", true, nil)
+	leaf := newTestCert(t, "leaf", false, ca)
+	self := newTestCert(t, "self", false, nil)
+	other := newTestCert(t, "other", true, nil)
+
+	randomPin := base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	tests := []struct {
+		name  string
+		chain []*testCert
+		opts  TLSOptions
+		code  ErrorCode
+	}{
+		{
+			name:  "закреплён ключ CA",
+			chain: []*testCert{leaf, ca},
+			opts:  TLSOptions{CAPEM: ca.pem(), OnlyCA: true, PinnedSPKI: []string{"sha256/" + SPKIPin(ca.cert)}},
+		},
+		{
+			name:  "закреплён ключ сервера",
+			chain: []*testCert{leaf},
+			opts:  TLSOptions{CAPEM: ca.pem(), OnlyCA: true, PinnedSPKI: []string{SPKIPin(leaf.cert)}},
+		},
+		{
+			name:  "ключ не совпал",
+			chain: []*testCert{leaf, ca},
+			opts:  TLSOptions{CAPEM: ca.pem(), OnlyCA: true, PinnedSPKI: []string{randomPin}},
+			code:  CodeTLSPinMismatch,
+		},
+		{
+			name:  "неизвестный CA",
+			chain: []*testCert{leaf, ca},
+			opts:  TLSOptions{},
+			code:  CodeTLSUnknownAuthority,
+		},
+		{
+			name:  "закреплённый сертификат дописан в цепочку",
+			chain: []*testCert{leaf, ca, other},
+			opts:  TLSOptions{CAPEM: ca.pem(), OnlyCA: true, PinnedSPKI: []string{SPKIPin(other.cert)}},
+			code:  CodeTLSPinMismatch,
+		},
+		{
+			name:  "без проверки цепочки: закреплённый сертификат дописан к самоподписанному",
+			chain: []*testCert{self, other},
+			opts:  TLSOptions{InsecureSkipVerify: true, PinnedSPKI: []string{SPKIPin(other.cert)}},
+			code:  CodeTLSPinMismatch,
+		},
+		{
+			name:  "без проверки цепочки: закреплён ключ сервера",
+			chain: []*testCert{self},
+			opts:  TLSOptions{InsecureSkipVerify: true, PinnedSPKI: []string{SPKIPin(self.cert)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTLSServer(t, tt.chain...)
+
+			cfg, err := tt.opts.Config()
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := NewClient(WithTLSConfig(cfg))
+			_, err = c.GetSites(context.Background(), GetSitesInput{Id: "1", Config: Config{Host: srv.URL, ApiKey: "key"}})
+
+			if got := CodeOf(err); got != tt.code {
+				t.Fatalf("код ошибки %q, ожидался %q (%v)", got, tt.code, err)
+			}
+		})
+	}
+}
+
+func TestTLSOptionsInvalidPin(t *testing.T) {
+	_, err := TLSOptions{PinnedSPKI: []string{"not-a-pin"}}.Config()
+	if CodeOf(err) != CodeTLSPinInvalid {
+		t.Fatalf("ошибка %v, ожидался код %q", err, CodeTLSPinInvalid)
+	}
+}
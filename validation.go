@@ -0,0 +1,96 @@
+package andromeda
+
+import (
+	"errors"
+	"strings"
+)
+
+// Правила проверки входных данных
+const (
+	RuleRequired = "required" //Поле обязательно
+	RuleRange    = "range"    //Значение вне допустимого диапазона
+	RuleFormat   = "format"   //Неверный формат значения
+	RuleOneOf    = "one_of"   //Значение не входит в список допустимых
+)
+
+type (
+	//Ошибка проверки одного поля входной структуры
+	FieldError struct {
+		Field   string    `json:"field"`   //Имя поля входной структуры, например SiteId или Config.Host
+		Rule    string    `json:"rule"`    //Нарушенное правило: RuleRequired, RuleRange, RuleFormat или RuleOneOf
+		Code    ErrorCode `json:"code"`    //Код ошибки
+		Message string    `json:"message"` //Описание ошибки на языке клиента
+	}
+
+	//Ошибка проверки входных данных со списком всех неверно заполненных полей.
+	//Все методы клиента возвращают её до отправки запроса; поля проверяются через errors.As и Field
+	ValidationError struct {
+		Fields []FieldError `json:"fields"`
+	}
+
+	//Накопление ошибок проверки полей
+	validator struct {
+		fields []FieldError
+	}
+)
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for idx, f := range e.Fields {
+		msgs[idx] = f.Message
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// Ошибка поля по имени, nil если поле заполнено верно
+func (e *ValidationError) Field(name string) *FieldError {
+	for idx := range e.Fields {
+		if e.Fields[idx].Field == name {
+			return &e.Fields[idx]
+		}
+	}
+
+	return nil
+}
+
+func (v *validator) add(field, rule string, code ErrorCode) {
+	v.fields = append(v.fields, FieldError{Field: field, Rule: rule, Code: code, Message: code.Message(LangRU)})
+}
+
+func (v *validator) required(field, value string, code ErrorCode) {
+	if value == "" {
+		v.add(field, RuleRequired, code)
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Fields: v.fields}
+}
+
+// Проверка входной структуры и общих параметров с объединением ошибок всех полей. Сообщения на языке lang
+func validateInput(in input, keyRequired bool, lang Lang) error {
+	var fields []FieldError
+	for _, err := range []error{in.validate(), in.validateConfig(keyRequired)} {
+		if err == nil {
+			continue
+		}
+		var ve *ValidationError
+		if !errors.As(err, &ve) {
+			return err
+		}
+		for _, f := range ve.Fields {
+			f.Message = f.Code.Message(lang)
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Fields: fields}
+}
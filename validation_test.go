@@ -0,0 +1,119 @@
+package andromeda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// Поле, правило и код ошибки проверки в виде строки
+func fieldKey(f FieldError) string {
+	return f.Field + " " + f.Rule + " " + string(f.Code)
+}
+
+func TestValidateInput(t *testing.T) {
+	cfg := Config{Host: "https://andromeda.local", ApiKey: "key"}
+
+	tests := []struct {
+		name        string
+		in          input
+		keyOptional bool //У клиента задан CredentialsProvider
+		want        []string
+	}{
+		{name: "верные данные", in: GetSitesInput{Id: "1", Config: cfg}},
+		{
+			name: "все ошибки сразу",
+			in:   PutChangeUserMyAlarmInput{Role: "owner"},
+			want: []string{"CustId required user_id_required", "Role one_of role_invalid", "Config.ApiKey required api_key_required", "Config.Host required host_required"},
+		},
+		{
+			name:        "ключ из источника ключей",
+			in:          GetZonesInput{SiteId: "s1", Config: Config{Host: cfg.Host}},
+			keyOptional: true,
+		},
+		{
+			name: "интервал проверки КТС вне диапазона",
+			in:   PostCheckPanicInput{SiteId: "s1", CheckInterval: 30, Config: cfg},
+			want: []string{"CheckInterval range check_interval_range"},
+		},
+		{
+			name: "интервал проверки КТС в диапазоне",
+			in:   PostCheckPanicInput{SiteId: "s1", CheckInterval: 31, Config: cfg},
+		},
+		{
+			name: "телефон не в формате +7XXXXXXXXXX",
+			in:   GetUserObjectMyAlarmInput{Phone: "89001234567", Config: cfg},
+			want: []string{"Phone format phone_format"},
+		},
+		{
+			name: "телефон не задан",
+			in:   GetUserObjectMyAlarmInput{Config: cfg},
+			want: []string{"Phone required phone_required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateInput(tt.in, !tt.keyOptional, LangRU)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			var ve *ValidationError
+			if !errors.As(err, &ve) {
+				t.Fatalf("ошибка %v, ожидалась ValidationError", err)
+			}
+			if len(ve.Fields) != len(tt.want) {
+				t.Fatalf("ошибок %d, ожидалось %d: %+v", len(ve.Fields), len(tt.want), ve.Fields)
+			}
+			for idx, f := range ve.Fields {
+				if got := fieldKey(f); got != tt.want[idx] {
+					t.Errorf("ошибка %d: %q, ожидалась %q", idx, got, tt.want[idx])
+				}
+			}
+			if CodeOf(err) != CodeValidationFailed {
+				t.Errorf("код ошибки %q, ожидался %q", CodeOf(err), CodeValidationFailed)
+			}
+		})
+	}
+}
+
+func TestValidationErrorLanguage(t *testing.T) {
+	_, err := NewClient(WithLanguage(LangEN)).GetSites(context.Background(), GetSitesInput{})
+
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("ошибка %v, ожидалась ValidationError", err)
+	}
+	want := CodeSiteNumberRequired.Message(LangEN) + "; " + CodeApiKeyRequired.Message(LangEN) + "; " + CodeHostRequired.Message(LangEN)
+	if ve.Error() != want {
+		t.Fatalf("сообщение %q, ожидалось %q", ve.Error(), want)
+	}
+
+	ru := Localize(err, LangRU).(*ValidationError)
+	if ru.Field("Id").Message != CodeSiteNumberRequired.Message(LangRU) {
+		t.Fatalf("перевод %q", ru.Field("Id").Message)
+	}
+	if ve.Field("Id").Message != CodeSiteNumberRequired.Message(LangEN) {
+		t.Fatal("Localize изменил исходную ошибку")
+	}
+	if ve.Field("SiteId") != nil {
+		t.Fatal("найдена ошибка незаданного поля")
+	}
+}
+
+func TestValidationErrorJSON(t *testing.T) {
+	ve := &ValidationError{Fields: []FieldError{{Field: "SiteId", Rule: RuleRequired, Code: CodeSiteIdRequired, Message: "m"}}}
+
+	data, err := json.Marshal(ve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"fields":[{"field":"SiteId","rule":"required","code":"site_id_required","message":"m"}]}`; string(data) != want {
+		t.Fatalf("%s, ожидалось %s", data, want)
+	}
+}